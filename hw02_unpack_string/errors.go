@@ -0,0 +1,40 @@
+package hw02unpackstring
+
+import "fmt"
+
+// Rule names the packing rule broken by the input.
+type Rule string
+
+const (
+	RuleLeadingDigit        Rule = "leading digit"
+	RuleConsecutiveDigits   Rule = "consecutive digits"
+	RuleDanglingEscape      Rule = "dangling escape"
+	RuleDisallowedCharacter Rule = "disallowed character"
+)
+
+// UnpackError points to the first invalid character of a packed string.
+// It wraps ErrInvalidString or ErrIncorrectCharacters, so errors.Is keeps working.
+type UnpackError struct {
+	// Offset is the byte offset of Char in the input.
+	Offset int
+	// Char is the offending rune, utf8.RuneError for invalid UTF-8.
+	Char rune
+	Rule Rule
+	Err  error
+}
+
+func newUnpackError(offset int, char rune, rule Rule) *UnpackError {
+	err := ErrInvalidString
+	if rule == RuleDisallowedCharacter {
+		err = ErrIncorrectCharacters
+	}
+	return &UnpackError{Offset: offset, Char: char, Rule: rule, Err: err}
+}
+
+func (e *UnpackError) Error() string {
+	return fmt.Sprintf("%v: %s %q at byte %d", e.Err, e.Rule, e.Char, e.Offset)
+}
+
+func (e *UnpackError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,80 @@
+package hw02unpackstring
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRepeat is the largest count Unpack accepts after a single character.
+const maxRepeat = 9
+
+// Pack is the inverse of Unpack: it returns the shortest string s such that Unpack(s) == inputStr.
+func Pack(inputStr string) (string, error) {
+	var builder strings.Builder
+
+	// 1. Check inputs before packing.
+	if len(inputStr) == 0 {
+		return "", nil
+	}
+	// 1.1. Unpack would reject such characters, so they cannot be packed either.
+	if err := checkCharacters(inputStr); err != nil {
+		return "", err
+	}
+
+	// 2. Break into characters the same way Unpack does.
+	clusters := splitIntoClusters(inputStr)
+
+	// 3. Encode every run of equal characters.
+	for i := 0; i < len(clusters); {
+		count := 1
+		for i+count < len(clusters) && clusters[i+count] == clusters[i] {
+			count++
+		}
+		encoded := escape(clusters[i])
+		// 3.1. Unpack forbids multi-digit counts, so long runs are split into chunks.
+		for left := count; left > 0; left -= maxRepeat {
+			writeRun(&builder, encoded, min(left, maxRepeat))
+		}
+		i += count
+	}
+
+	return builder.String(), nil
+}
+
+// A character is a rune followed by all of its combining marks.
+// Clusters are slices of input, so no character is copied.
+func splitIntoClusters(input string) []string {
+	clusters := make([]string, 0, utf8.RuneCountInString(input))
+	start := 0
+	for offset, char := range input {
+		if offset == 0 || isCombiningMark(char) {
+			continue
+		}
+		clusters = append(clusters, input[start:offset])
+		start = offset
+	}
+	if start < len(input) {
+		clusters = append(clusters, input[start:])
+	}
+	return clusters
+}
+
+// Digits and backslashes at the start of a character must be escaped.
+func escape(cluster string) string {
+	first, _ := utf8.DecodeRuneInString(cluster)
+	if isDigit(first) || isEscapeCharacter(first) {
+		return `\` + cluster
+	}
+	return cluster
+}
+
+// Writes the shorter of "xxx" and "x3", preferring the literal form on a tie.
+func writeRun(builder *strings.Builder, encoded string, count int) {
+	if count*len(encoded) <= len(encoded)+1 {
+		builder.WriteString(strings.Repeat(encoded, count))
+		return
+	}
+	builder.WriteString(encoded)
+	builder.WriteString(strconv.Itoa(count))
+}
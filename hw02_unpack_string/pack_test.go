@@ -0,0 +1,83 @@
+package hw02unpackstring
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "", expected: ""},
+		{input: "abccd", expected: "abccd"},
+		{input: "aaaabccddddde", expected: "a4bccd5e"},
+		{input: "qwe45", expected: `qwe\4\5`},
+		{input: "qwe44444", expected: `qwe\45`},
+		{input: `qwe\\\\\`, expected: `qwe\\5`},
+		{input: `qwe\3`, expected: `qwe\\\3`},
+		{input: "пппёё", expected: "п3ё2"},
+		{input: "жж", expected: "ж2"},
+		{input: "ёё", expected: "ё2"},
+		{input: "\u0435\u0308\u0435\u0308", expected: "\u0435\u03082"},
+		{input: strings.Repeat("a", 10), expected: "a9a"},
+		{input: strings.Repeat("a", 20), expected: "a9a9aa"},
+		{input: strings.Repeat("7", 23), expected: `\79\79\75`},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := Pack(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestPackLongCombiningSequence(t *testing.T) {
+	cluster := "a" + strings.Repeat("\u0301", 500_000)
+	start := time.Now()
+	result, err := Pack(cluster + cluster + "b")
+	require.Less(t, time.Since(start), 2*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, cluster+"2b", result)
+}
+
+func TestPackIncorrectCharacters(t *testing.T) {
+	invalidCharacters := []string{"ab\xff", "п\x00"}
+	for _, tc := range invalidCharacters {
+		tc := tc
+		t.Run(tc, func(t *testing.T) {
+			_, err := Pack(tc)
+			require.Truef(t, errors.Is(err, ErrIncorrectCharacters), "actual error %q", err)
+		})
+	}
+}
+
+func FuzzPackUnpack(f *testing.F) {
+	seeds := []string{
+		"", "a", "aaaabccddddde", "qwe45", `qwe\\\\\`, "пппёё", "🙂🙂🙂",
+		"\u0301\u0301", "a\u0301a\u0301", "55\u0301", strings.Repeat("x", 28),
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		packed, err := Pack(input)
+		if errors.Is(err, ErrIncorrectCharacters) {
+			t.Skip()
+		}
+		require.NoError(t, err)
+
+		unpacked, err := Unpack(packed)
+		require.NoError(t, err, "packed %q", packed)
+		require.Equal(t, input, unpacked, "packed %q", packed)
+		require.LessOrEqual(t, len(packed), 2*len(input))
+	})
+}
@@ -0,0 +1,101 @@
+package hw02unpackstring
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// UnpackStream decodes r into w incrementally, following the same rules as Unpack.
+// Memory use is bounded by the longest character (a rune with its combining marks),
+// not by the input size. Invalid input is reported as *UnpackError, but w may already
+// hold the output decoded before it.
+func UnpackStream(r io.Reader, w io.Writer) error {
+	d := streamDecoder{out: bufio.NewWriter(w)}
+	if err := d.decode(bufio.NewReader(r)); err != nil {
+		return err
+	}
+	return d.out.Flush()
+}
+
+type streamDecoder struct {
+	out *bufio.Writer
+	// pending is the last character read; it is written once we know it is not followed by a count
+	pending     []byte
+	openCluster bool
+	escapeMode  bool
+}
+
+func (d *streamDecoder) decode(in io.RuneReader) error {
+	offset := 0
+	for {
+		char, size, err := in.ReadRune()
+		if errors.Is(err, io.EOF) {
+			if d.escapeMode {
+				return newUnpackError(offset-1, '\\', RuleDanglingEscape)
+			}
+			return d.flushPending()
+		}
+		if err != nil {
+			return err
+		}
+		if char == utf8.RuneError && size == 1 || unicode.IsControl(char) && !unicode.IsSpace(char) {
+			return newUnpackError(offset, char, RuleDisallowedCharacter)
+		}
+		if err := d.next(char, offset); err != nil {
+			return err
+		}
+		offset += size
+	}
+}
+
+func (d *streamDecoder) next(char rune, offset int) error {
+	switch {
+	case d.escapeMode:
+		d.escapeMode = false
+		return d.startCluster(char)
+	case isEscapeCharacter(char):
+		d.escapeMode = true
+		return d.flushPending()
+	case isDigit(char):
+		// a count must follow a character, never another count
+		if offset == 0 {
+			return newUnpackError(offset, char, RuleLeadingDigit)
+		}
+		if !d.openCluster {
+			return newUnpackError(offset, char, RuleConsecutiveDigits)
+		}
+		d.openCluster = false
+		return d.writeRepeated(runeToDigit(char))
+	case d.openCluster && isCombiningMark(char):
+		d.pending = utf8.AppendRune(d.pending, char)
+		return nil
+	default:
+		return d.startCluster(char)
+	}
+}
+
+func (d *streamDecoder) startCluster(char rune) error {
+	if err := d.flushPending(); err != nil {
+		return err
+	}
+	d.pending = utf8.AppendRune(d.pending, char)
+	d.openCluster = true
+	return nil
+}
+
+func (d *streamDecoder) flushPending() error {
+	return d.writeRepeated(1)
+}
+
+func (d *streamDecoder) writeRepeated(count int) error {
+	for i := 0; i < count; i++ {
+		if _, err := d.out.Write(d.pending); err != nil {
+			return err
+		}
+	}
+	d.pending = d.pending[:0]
+	return nil
+}
@@ -0,0 +1,90 @@
+package hw02unpackstring
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpackStream(t *testing.T) {
+	inputs := []string{
+		"a4bc2d5e", "abccd", "", "aaa0b", `qwe\4\5`, `qwe\45`, `qwe\\5`, `qwe\\\3`,
+		"п3ё2", "🙂3x", "\u0435\u03082", "a\u0301\u03083", "d\n5abc",
+	}
+	for _, tc := range inputs {
+		tc := tc
+		t.Run(tc, func(t *testing.T) {
+			expected, err := Unpack(tc)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			require.NoError(t, UnpackStream(strings.NewReader(tc), &out))
+			require.Equal(t, expected, out.String())
+		})
+	}
+}
+
+func TestUnpackStreamErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected error
+		offset   int
+	}{
+		{input: "3abc", expected: ErrInvalidString, offset: 0},
+		{input: "45", expected: ErrInvalidString, offset: 0},
+		{input: "aaa10b", expected: ErrInvalidString, offset: 4},
+		{input: "пп23", expected: ErrInvalidString, offset: 5},
+		{input: `ё\\45`, expected: ErrInvalidString, offset: 5},
+		{input: `abc\`, expected: ErrInvalidString, offset: 3},
+		{input: "ab\xff2", expected: ErrIncorrectCharacters, offset: 2},
+		{input: "п\x003", expected: ErrIncorrectCharacters, offset: 2},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			err := UnpackStream(strings.NewReader(tc.input), io.Discard)
+			require.Truef(t, errors.Is(err, tc.expected), "actual error %q", err)
+			var unpackErr *UnpackError
+			require.ErrorAs(t, err, &unpackErr)
+			require.Equal(t, tc.offset, unpackErr.Offset)
+		})
+	}
+}
+
+func TestUnpackStreamLargeInput(t *testing.T) {
+	const chunks = 1 << 16
+	in := io.MultiReader(
+		strings.NewReader(strings.Repeat("ж9", chunks)),
+		strings.NewReader("ok"),
+	)
+	var out countingWriter
+	require.NoError(t, UnpackStream(in, &out))
+	require.Equal(t, chunks*9*len("ж")+len("ok"), out.n)
+}
+
+func TestUnpackStreamWriteError(t *testing.T) {
+	errWrite := errors.New("write failed")
+	err := UnpackStream(strings.NewReader(strings.Repeat("a9", 1<<12)), failingWriter{err: errWrite})
+	require.ErrorIs(t, err, errWrite)
+}
+
+type countingWriter struct {
+	n int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
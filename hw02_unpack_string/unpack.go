@@ -2,37 +2,47 @@ package hw02unpackstring
 
 import (
 	"errors"
-	"strconv"
+	"math"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 var (
 	ErrInvalidString       = errors.New("invalid string")
-	ErrIncorrectCharacters = errors.New("non-latin characters are not supported")
+	ErrIncorrectCharacters = errors.New("invalid UTF-8 or control characters are not supported")
+	ErrTooLong             = errors.New("unpacked string exceeds maximum length")
 )
 
+// Options tune UnpackWithOptions. The zero value is the strict mode used by Unpack.
+type Options struct {
+	// MultiDigitCounts allows counts of more than one digit, e.g. "a12" => 12 × "a".
+	MultiDigitCounts bool
+	// MaxLength limits the unpacked string length in bytes. Zero means no limit.
+	MaxLength int
+}
+
 func Unpack(inputStr string) (string, error) {
+	return UnpackWithOptions(inputStr, Options{})
+}
+
+func UnpackWithOptions(inputStr string, opts Options) (string, error) {
 	var builder strings.Builder
 
 	// 1. Check inputs before unpacking.
 	if len(inputStr) == 0 {
 		return "", nil
 	}
-	// 1.1. There must be no 2+ adjacent digits in input string.
-	if consecutiveDigitsExist(inputStr) {
-		return "", ErrInvalidString
-	}
-	// 1.2. Input string must start with a alphabetic character.
-	if startsWithDigit(inputStr) {
-		return "", ErrInvalidString
-	}
-	// 1.3. Method supports only latin characters.
-	if containsNotAllowedCharacters(inputStr) {
-		return "", ErrIncorrectCharacters
+	// 1.1. Input must follow the packing rules, the first broken one is reported.
+	if err := validate(inputStr, opts.MultiDigitCounts); err != nil {
+		return "", err
 	}
 
 	// 2. Break into a slice.
-	inputSlice := unpackIntoSlice(inputStr)
+	inputSlice, err := unpackIntoSlice([]rune(inputStr), opts.MaxLength)
+	if err != nil {
+		return "", err
+	}
 
 	// 3. Reduce a slice of substrings into string.
 
@@ -43,70 +53,148 @@ func Unpack(inputStr string) (string, error) {
 	return builder.String(), nil
 }
 
-func startsWithDigit(inputStr string) bool {
-	return isDigit(inputStr[0])
-}
-
-func unpackIntoSlice(inputStr string) []string {
-	unpacked := make([]string, len(inputStr))
+func unpackIntoSlice(input []rune, maxLength int) ([]string, error) {
+	if maxLength <= 0 {
+		maxLength = math.MaxInt
+	}
+	unpacked := make([]string, 0, len(input))
 	escapeMode := false
-	// iterate,  if digit     -> replace previous character (with itself *digit times)
-	// 			 if character -> add to slice
-	for i, char := range []byte(inputStr) {
+	// cluster is the last character read while it may still take combining marks,
+	// it is added to unpacked once closed
+	var cluster []byte
+	openCluster := false
+	closeCluster := func() {
+		if openCluster {
+			unpacked = append(unpacked, string(cluster))
+			cluster = cluster[:0]
+			openCluster = false
+		}
+	}
+	// a count is applied once all of its digits are read
+	count, countPending := 0, false
+	length := 0
+	// iterate,  if digit          -> replace previous character (with itself *count times)
+	// 			 if combining mark -> attach to previous character
+	// 			 if character      -> add to slice
+	for _, char := range input {
+		if !escapeMode && isDigit(char) {
+			if count > (maxLength-runeToDigit(char))/10 {
+				return nil, ErrTooLong
+			}
+			closeCluster()
+			count = count*10 + runeToDigit(char)
+			countPending = true
+			continue
+		}
+		if countPending {
+			if err := repeatLast(unpacked, count, &length, maxLength); err != nil {
+				return nil, err
+			}
+			count, countPending = 0, false
+		}
 		if !escapeMode && isEscapeCharacter(char) {
 			escapeMode = true
 			continue
 		}
-		if !escapeMode && isDigit(char) {
-			unpacked[i-1] = strings.Repeat(unpacked[i-1], int(byteToDigit(char)))
+		if !escapeMode && openCluster && isCombiningMark(char) {
+			cluster = utf8.AppendRune(cluster, char)
 		} else {
-			unpacked[i] = string(char)
+			closeCluster()
+			cluster = utf8.AppendRune(cluster, char)
+			openCluster = true
+		}
+		length += utf8.RuneLen(char)
+		if length > maxLength {
+			return nil, ErrTooLong
 		}
 		escapeMode = false
 	}
-	return unpacked
+	closeCluster()
+	if countPending {
+		if err := repeatLast(unpacked, count, &length, maxLength); err != nil {
+			return nil, err
+		}
+	}
+	return unpacked, nil
 }
 
-func consecutiveDigitsExist(in string) bool {
-	isDigitPrevious := isDigit(in[0])
+// Replaces the last element with itself repeated count times, keeping length within maxLength.
+func repeatLast(unpacked []string, count int, length *int, maxLength int) error {
+	last := len(unpacked) - 1
+	size := len(unpacked[last])
+	if count > 1 && size > (maxLength-*length)/(count-1) {
+		return ErrTooLong
+	}
+	*length += size * (count - 1)
+	unpacked[last] = strings.Repeat(unpacked[last], count)
+	return nil
+}
 
-	for i := 1; i < len(in); i++ {
-		isDigitCurrent := isDigit(in[i]) && !isEscapeCharacter(in[i-1])
-		if isDigitPrevious && isDigitCurrent {
-			return true
+// Checks, in input order: allowed characters, no leading digit,
+// no 2+ adjacent digits (unless allowed) and no escape at the end.
+func validate(inputStr string, multiDigitCounts bool) error {
+	isDigitPrevious := false
+	escapeMode := false
+
+	for offset, char := range inputStr {
+		if !isAllowedCharacter(inputStr[offset:]) {
+			return newUnpackError(offset, char, RuleDisallowedCharacter)
+		}
+		isDigitCurrent := false
+		switch {
+		case escapeMode:
+			escapeMode = false
+		case isEscapeCharacter(char):
+			escapeMode = true
+		default:
+			isDigitCurrent = isDigit(char)
+		}
+		if isDigitCurrent && offset == 0 {
+			return newUnpackError(offset, char, RuleLeadingDigit)
+		}
+		if isDigitPrevious && isDigitCurrent && !multiDigitCounts {
+			return newUnpackError(offset, char, RuleConsecutiveDigits)
 		}
 		isDigitPrevious = isDigitCurrent
 	}
-	return false
+	if escapeMode {
+		return newUnpackError(len(inputStr)-1, '\\', RuleDanglingEscape)
+	}
+	return nil
 }
 
-func isDigit(in byte) bool {
-	if _, err := strconv.Atoi(string(in)); err == nil {
-		return true
-	}
-	return false
+// Only ASCII digits are treated as repeat counts.
+func isDigit(char rune) bool {
+	return char >= '0' && char <= '9'
 }
 
-func byteToDigit(in byte) int8 {
-	value, _ := strconv.Atoi(string(in))
-	return int8(value)
+func runeToDigit(char rune) int {
+	return int(char - '0')
 }
 
-func isEscapeCharacter(char byte) bool {
+func isEscapeCharacter(char rune) bool {
 	return char == '\\'
 }
 
-// Allowed: a Latin lettes, digits, forward slash or backslash.
-func containsNotAllowedCharacters(str string) bool {
-	for _, char := range str {
-		if (char >= 'a' && char <= 'z') ||
-			(char >= 'A' && char <= 'Z') ||
-			(char >= '0' && char <= '9') ||
-			char == 47 ||
-			char == 92 {
-			continue
+// Combining marks (e.g. U+0308 in "е\u0308" for "ё") belong to the preceding character.
+func isCombiningMark(char rune) bool {
+	return unicode.In(char, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+// Allowed: any valid UTF-8 character except control characters other than whitespace.
+func isAllowedCharacter(str string) bool {
+	char, size := utf8.DecodeRuneInString(str)
+	if char == utf8.RuneError && size <= 1 {
+		return false
+	}
+	return !unicode.IsControl(char) || unicode.IsSpace(char)
+}
+
+func checkCharacters(str string) error {
+	for offset, char := range str {
+		if !isAllowedCharacter(str[offset:]) {
+			return newUnpackError(offset, char, RuleDisallowedCharacter)
 		}
-		return true
 	}
-	return false
+	return nil
 }
@@ -2,7 +2,11 @@ package hw02unpackstring
 
 import (
 	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 )
@@ -48,12 +52,137 @@ func TestUnpackInvalidString(t *testing.T) {
 	}
 }
 
-func TestUnpackSpecificUTF(t *testing.T) {
-	invalidCharacters := []string{"žƃƟƩ", "ƺ1ǟ2Ȣ3"}
+func TestUnpackUnicode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "п3ё2", expected: "пппёё"},
+		{input: "привет", expected: "привет"},
+		{input: "žƃ2Ɵ0Ʃ", expected: "žƃƃƩ"},
+		{input: "🙂3x", expected: "🙂🙂🙂x"},
+		{input: "\u0435\u03082", expected: "\u0435\u0308\u0435\u0308"},
+		{input: "a\u0301\u03083", expected: "a\u0301\u0308a\u0301\u0308a\u0301\u0308"},
+		{input: `ж\3\\2`, expected: `ж3\\`},
+		{input: "d\n5abc", expected: "d\n\n\n\n\nabc"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := Unpack(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestUnpackUnicodeInvalidString(t *testing.T) {
+	invalidStrings := []string{"3ж", "ж45", `ё\\45`, "🙂10", `abc\`}
+	for _, tc := range invalidStrings {
+		tc := tc
+		t.Run(tc, func(t *testing.T) {
+			_, err := Unpack(tc)
+			require.Truef(t, errors.Is(err, ErrInvalidString), "actual error %q", err)
+		})
+	}
+}
+
+func TestUnpackIncorrectCharacters(t *testing.T) {
+	invalidCharacters := []string{"ab\xff2", "п\x003", "\x1b[0m"}
 	for _, tc := range invalidCharacters {
+		tc := tc
 		t.Run(tc, func(t *testing.T) {
 			_, err := Unpack(tc)
-			require.EqualError(t, err, ErrIncorrectCharacters.Error())
+			require.Truef(t, errors.Is(err, ErrIncorrectCharacters), "actual error %q", err)
+		})
+	}
+}
+
+func TestUnpackWithOptions(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     Options
+		expected string
+	}{
+		{input: "a4bc2d5e", opts: Options{}, expected: "aaaabccddddde"},
+		{input: "a12", opts: Options{MultiDigitCounts: true}, expected: strings.Repeat("a", 12)},
+		{input: "a10b00c", opts: Options{MultiDigitCounts: true}, expected: "aaaaaaaaaac"},
+		{input: `ж\12`, opts: Options{MultiDigitCounts: true}, expected: "ж11"},
+		{input: `\\15`, opts: Options{MultiDigitCounts: true}, expected: strings.Repeat(`\`, 15)},
+		{input: "a9b", opts: Options{MaxLength: 10}, expected: "aaaaaaaaab"},
+		{input: "п5", opts: Options{MaxLength: 10}, expected: "ппппп"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := UnpackWithOptions(tc.input, tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestUnpackLongCombiningSequence(t *testing.T) {
+	// a character with many combining marks is built in linear time
+	cluster := "a" + strings.Repeat("\u0301", 500_000)
+	start := time.Now()
+	result, err := UnpackWithOptions(cluster+"2b", Options{MaxLength: 1 << 22})
+	require.Less(t, time.Since(start), 2*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, cluster+cluster+"b", result)
+}
+
+func TestUnpackWithOptionsErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     Options
+		expected error
+	}{
+		{input: "a12", opts: Options{}, expected: ErrInvalidString},
+		{input: "12a", opts: Options{MultiDigitCounts: true}, expected: ErrInvalidString},
+		{input: "a9bb", opts: Options{MaxLength: 10}, expected: ErrTooLong},
+		{input: "п6", opts: Options{MaxLength: 10}, expected: ErrTooLong},
+		{input: "a1000000000000", opts: Options{MultiDigitCounts: true, MaxLength: 1 << 20}, expected: ErrTooLong},
+		{input: "a99999999999999999999999", opts: Options{MultiDigitCounts: true}, expected: ErrTooLong},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := UnpackWithOptions(tc.input, tc.opts)
+			require.Truef(t, errors.Is(err, tc.expected), "actual error %q", err)
+		})
+	}
+}
+
+func TestUnpackErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected UnpackError
+	}{
+		{input: "3abc", expected: UnpackError{0, '3', RuleLeadingDigit, ErrInvalidString}},
+		{input: "aaa10b", expected: UnpackError{4, '0', RuleConsecutiveDigits, ErrInvalidString}},
+		{input: `ё\\45`, expected: UnpackError{5, '5', RuleConsecutiveDigits, ErrInvalidString}},
+		{input: `qw\`, expected: UnpackError{2, '\\', RuleDanglingEscape, ErrInvalidString}},
+		{input: "ab\xff2", expected: UnpackError{2, utf8.RuneError, RuleDisallowedCharacter, ErrIncorrectCharacters}},
+		{input: "п\x003", expected: UnpackError{2, 0, RuleDisallowedCharacter, ErrIncorrectCharacters}},
+		{input: "44\x00", expected: UnpackError{0, '4', RuleLeadingDigit, ErrInvalidString}},
+		{input: "a44\x00", expected: UnpackError{2, '4', RuleConsecutiveDigits, ErrInvalidString}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := Unpack(tc.input)
+			var unpackErr *UnpackError
+			require.ErrorAs(t, err, &unpackErr)
+			require.Equal(t, tc.expected, *unpackErr)
+			require.ErrorIs(t, err, tc.expected.Err)
+
+			streamErr := UnpackStream(strings.NewReader(tc.input), io.Discard)
+			require.Equal(t, err, streamErr)
 		})
 	}
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+var ErrInvalidMaxError = errors.New("max error must be in (0, 1)")
+
+// WordCounter is implemented by both the exact Counter and the ApproxCounter,
+// so callers may pick one depending on the input size.
+type WordCounter interface {
+	Add(r io.Reader) error
+	Top(n int) []WordCount
+}
+
+var (
+	_ WordCounter = (*Counter)(nil)
+	_ WordCounter = (*ApproxCounter)(nil)
+)
+
+// ApproxCounter finds the most frequent words of an unbounded stream in fixed
+// memory using the Space-Saving algorithm. It is safe for concurrent use.
+//
+// With maxError ε it tracks ⌈1/ε⌉ words. For a stream of N words every word
+// occurring more than εN times is reported, and reported counts exceed the
+// real ones by at most εN.
+type ApproxCounter struct {
+	cfg      config
+	workers  int
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*approxEntry
+	// byCount is a min-heap, its root is replaced when a new word arrives
+	byCount approxHeap
+}
+
+type approxEntry struct {
+	word  string
+	count int
+	index int
+}
+
+func NewApproxCounter(maxError float64, opts ...Option) (*ApproxCounter, error) {
+	if maxError <= 0 || maxError >= 1 {
+		return nil, ErrInvalidMaxError
+	}
+	capacity := int(math.Ceil(1 / maxError))
+	return &ApproxCounter{
+		cfg:      newConfig(opts),
+		workers:  runtime.GOMAXPROCS(0),
+		capacity: capacity,
+		entries:  make(map[string]*approxEntry, capacity),
+		byCount:  make(approxHeap, 0, capacity),
+	}, nil
+}
+
+// Add counts the words read from r. Unlike Counter, words read before
+// a read error stay counted.
+func (c *ApproxCounter) Add(r io.Reader) error {
+	chunks := make(chan string, c.workers)
+
+	// Workers sanitize chunks in parallel, the summary is updated under lock
+	wg := sync.WaitGroup{}
+	wg.Add(c.workers)
+	for i := 0; i < c.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				words := stemWords(sanititzeWords(c.cfg.tokenizer(chunk), c.cfg), c.cfg)
+				c.mu.Lock()
+				for _, w := range words {
+					c.offer(w)
+				}
+				c.mu.Unlock()
+			}
+		}()
+	}
+
+	err := readChunks(bufio.NewReaderSize(r, chunkSize), chunks)
+	close(chunks)
+	wg.Wait()
+	return err
+}
+
+func (c *ApproxCounter) offer(word string) {
+	if e, ok := c.entries[word]; ok {
+		e.count++
+		heap.Fix(&c.byCount, e.index)
+		return
+	}
+	if len(c.byCount) < c.capacity {
+		e := &approxEntry{word: word, count: 1}
+		c.entries[word] = e
+		heap.Push(&c.byCount, e)
+		return
+	}
+	// Replace the least frequent word, inheriting its count as the possible error
+	e := c.byCount[0]
+	delete(c.entries, e.word)
+	e.word = word
+	e.count++
+	c.entries[word] = e
+	heap.Fix(&c.byCount, 0)
+}
+
+// Top returns up to n most frequent words with estimated counts, ordered as in TopWithCounts.
+func (c *ApproxCounter) Top(n int) []WordCount {
+	if n <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	wordCount := make([]WordCount, 0, len(c.byCount))
+	for _, e := range c.byCount {
+		wordCount = append(wordCount, WordCount{Word: e.word, Count: e.count})
+	}
+	c.mu.Unlock()
+
+	sort.Slice(wordCount, func(i, j int) bool {
+		return ranksHigher(wordCount[i], wordCount[j])
+	})
+	return wordCount[:min(n, len(wordCount))]
+}
+
+type approxHeap []*approxEntry
+
+func (h approxHeap) Len() int           { return len(h) }
+func (h approxHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+
+func (h approxHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *approxHeap) Push(x any) {
+	e := x.(*approxEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *approxHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApproxCounter(t *testing.T) {
+	t.Run("invalid max error", func(t *testing.T) {
+		for _, maxError := range []float64{0, -0.1, 1, 2} {
+			_, err := NewApproxCounter(maxError)
+			require.ErrorIs(t, err, ErrInvalidMaxError)
+		}
+	})
+
+	t.Run("exact while words fit", func(t *testing.T) {
+		c, err := NewApproxCounter(0.001)
+		require.NoError(t, err)
+		require.NoError(t, c.Add(strings.NewReader(text)))
+		require.Equal(t, TopWithCounts(text, 10), c.Top(10))
+	})
+
+	t.Run("heavy hitters within error bound", func(t *testing.T) {
+		const maxError = 0.05
+		var sb strings.Builder
+		for i := 0; i < 200; i++ {
+			sb.WriteString("alpha alpha alpha beta beta gamma ")
+			// every noise word is seen once
+			sb.WriteString(strings.Repeat("x", i+1) + "\n")
+		}
+		input := sb.String()
+
+		c, err := NewApproxCounter(maxError)
+		require.NoError(t, err)
+		require.NoError(t, c.Add(strings.NewReader(input)))
+
+		exact := TopWithCounts(input, 3)
+		approx := c.Top(3)
+		total := 200 * 7
+		require.Len(t, approx, 3)
+		for i := range exact {
+			require.Equal(t, exact[i].Word, approx[i].Word)
+			require.GreaterOrEqual(t, approx[i].Count, exact[i].Count)
+			require.LessOrEqual(t, approx[i].Count, exact[i].Count+int(maxError*float64(total)))
+		}
+	})
+
+	t.Run("memory is bounded", func(t *testing.T) {
+		c, err := NewApproxCounter(0.1)
+		require.NoError(t, err)
+		require.NoError(t, c.Add(strings.NewReader(strings.Repeat(text+"\n", 10))))
+		require.Len(t, c.Top(1000), 10)
+		require.Len(t, c.entries, 10)
+	})
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+var (
+	ErrUnknownLanguage = errors.New("unknown language")
+	ErrUnknownFormat   = errors.New("unknown output format")
+)
+
+type cliOptions struct {
+	n             int
+	languages     string
+	stopWordsFile string
+	caseSensitive bool
+	stem          bool
+	format        string
+}
+
+// Counts words of files, or of stdin if there are none, and prints the top to stdout.
+func run(cli cliOptions, files []string, stdin io.Reader, stdout io.Writer) error {
+	opts, err := cli.counterOptions()
+	if err != nil {
+		return err
+	}
+	write, ok := writers[cli.format]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, cli.format)
+	}
+
+	counter := NewCounter(opts...)
+	if len(files) == 0 {
+		if err := counter.Add(stdin); err != nil {
+			return err
+		}
+	}
+	for _, name := range files {
+		if err := addFile(counter, name); err != nil {
+			return err
+		}
+	}
+
+	return write(stdout, counter.Top(cli.n))
+}
+
+func (cli cliOptions) counterOptions() ([]Option, error) {
+	var opts []Option
+	if cli.caseSensitive {
+		opts = append(opts, WithCaseFolding(PreserveCase))
+	}
+
+	var languages []Language
+	for _, lang := range strings.Split(cli.languages, ",") {
+		lang = strings.TrimSpace(lang)
+		if lang == "" {
+			continue
+		}
+		if _, ok := stopWords[Language(lang)]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownLanguage, lang)
+		}
+		languages = append(languages, Language(lang))
+	}
+	opts = append(opts, WithStopWords(languages...))
+	if cli.stem {
+		opts = append(opts, WithStemming(languages...))
+	}
+
+	if cli.stopWordsFile != "" {
+		words, err := readStopWords(cli.stopWordsFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithCustomStopWords(words...))
+	}
+	return opts, nil
+}
+
+func addFile(counter *Counter, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return counter.Add(f)
+}
+
+func readStopWords(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if w := strings.TrimSpace(scanner.Text()); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words, scanner.Err()
+}
+
+var writers = map[string]func(w io.Writer, top []WordCount) error{
+	formatTable: writeTable,
+	formatJSON:  writeJSON,
+	formatCSV:   writeCSV,
+}
+
+func writeTable(w io.Writer, top []WordCount) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, wc := range top {
+		fmt.Fprintf(tw, "%s\t%d\n", wc.Word, wc.Count)
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, top []WordCount) error {
+	if top == nil {
+		top = []WordCount{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(top)
+}
+
+func writeCSV(w io.Writer, top []WordCount) error {
+	cw := csv.NewWriter(w)
+	records := make([][]string, 0, len(top)+1)
+	records = append(records, []string{"word", "count"})
+	for _, wc := range top {
+		records = append(records, []string{wc.Word, strconv.Itoa(wc.Count)})
+	}
+	return cw.WriteAll(records)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	defaults := cliOptions{n: 10, format: formatTable}
+
+	t.Run("stdin table", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, run(defaults, nil, strings.NewReader("cat and dog, one dog"), &out))
+		expected := "dog  2\nand  1\ncat  1\none  1\n"
+		require.Equal(t, expected, out.String())
+	})
+
+	t.Run("files json", func(t *testing.T) {
+		dir := t.TempDir()
+		first := filepath.Join(dir, "first.txt")
+		second := filepath.Join(dir, "second.txt")
+		require.NoError(t, os.WriteFile(first, []byte("Нога ноги и рука"), 0o600))
+		require.NoError(t, os.WriteFile(second, []byte("ногу the cat"), 0o600))
+
+		cli := cliOptions{n: 2, languages: "ru, en", stem: true, format: formatJSON}
+		var out bytes.Buffer
+		require.NoError(t, run(cli, []string{first, second}, strings.NewReader("ignored"), &out))
+		expected := `[
+  {
+    "word": "ног",
+    "count": 3
+  },
+  {
+    "word": "cat",
+    "count": 1
+  }
+]
+`
+		require.Equal(t, expected, out.String())
+	})
+
+	t.Run("csv with stop words file and case sensitivity", func(t *testing.T) {
+		stopWordsFile := filepath.Join(t.TempDir(), "stop.txt")
+		require.NoError(t, os.WriteFile(stopWordsFile, []byte("dog\n\n  one \n"), 0o600))
+
+		cli := cliOptions{n: 10, stopWordsFile: stopWordsFile, caseSensitive: true, format: formatCSV}
+		var out bytes.Buffer
+		require.NoError(t, run(cli, nil, strings.NewReader("Cat cat, \"Dog\" one"), &out))
+		require.Equal(t, "word,count\nCat,1\ncat,1\n", out.String())
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, run(cliOptions{n: 10, format: formatJSON}, nil, strings.NewReader(""), &out))
+		require.Equal(t, "[]\n", out.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var out bytes.Buffer
+		err := run(cliOptions{n: 10, languages: "de", format: formatTable}, nil, strings.NewReader(""), &out)
+		require.ErrorIs(t, err, ErrUnknownLanguage)
+
+		err = run(cliOptions{n: 10, format: "xml"}, nil, strings.NewReader(""), &out)
+		require.ErrorIs(t, err, ErrUnknownFormat)
+
+		err = run(defaults, []string{filepath.Join(t.TempDir(), "missing.txt")}, nil, &out)
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		err = run(cliOptions{n: 10, stopWordsFile: "missing.txt", format: formatTable}, nil, nil, &out)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
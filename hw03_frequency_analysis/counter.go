@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// chunkSize is the approximate amount of text counted by one worker at a time.
+	chunkSize = 64 * 1024
+	// maxChunkExtension limits how far a chunk is extended to the next whitespace,
+	// so text without whitespace is still read in bounded pieces.
+	maxChunkExtension = 4 * 1024
+)
+
+// Counter counts words of arbitrarily large texts using all available cores.
+// It is safe for concurrent use.
+type Counter struct {
+	cfg     config
+	workers int
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewCounter(opts ...Option) *Counter {
+	return &Counter{
+		cfg:     newConfig(opts),
+		workers: runtime.GOMAXPROCS(0),
+		counts:  make(map[string]int),
+	}
+}
+
+// Add counts the words read from r. Text is split into chunks at whitespace,
+// so the tokenizer never sees a word broken across chunks unless the word
+// is longer than maxChunkExtension.
+// If reading fails, the counter is left unchanged.
+func (c *Counter) Add(r io.Reader) error {
+	chunks := make(chan string, c.workers)
+	shards := make([]map[string]int, c.workers)
+
+	// 1. Every worker counts its chunks into its own shard
+	wg := sync.WaitGroup{}
+	wg.Add(c.workers)
+	for i := range shards {
+		shards[i] = make(map[string]int)
+		go func(shard map[string]int) {
+			defer wg.Done()
+			for chunk := range chunks {
+				for _, w := range stemWords(sanititzeWords(c.cfg.tokenizer(chunk), c.cfg), c.cfg) {
+					shard[w]++
+				}
+			}
+		}(shards[i])
+	}
+
+	err := readChunks(bufio.NewReaderSize(r, chunkSize), chunks)
+	close(chunks)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	// 2. Merge shards
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, shard := range shards {
+		for w, count := range shard {
+			c.counts[w] += count
+		}
+	}
+	return nil
+}
+
+func readChunks(r *bufio.Reader, chunks chan<- string) error {
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		chunk := buf[:n]
+		if err == nil {
+			chunk, err = extendToSpace(r, chunk)
+		}
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		chunks <- string(chunk)
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// extendToSpace appends to chunk the rest of its last word, up to and including
+// the next whitespace, reading at most maxChunkExtension bytes.
+func extendToSpace(r *bufio.Reader, chunk []byte) ([]byte, error) {
+	for extended := 0; extended < maxChunkExtension; {
+		ch, size, err := r.ReadRune()
+		if err != nil {
+			return chunk, err
+		}
+		if ch == utf8.RuneError && size == 1 {
+			// keep the original byte: the chunk may end in the middle of a rune
+			_ = r.UnreadRune()
+			b, _ := r.ReadByte()
+			chunk = append(chunk, b)
+		} else {
+			chunk = utf8.AppendRune(chunk, ch)
+		}
+		if unicode.IsSpace(ch) {
+			break
+		}
+		extended += size
+	}
+	return chunk, nil
+}
+
+// Top returns up to n most frequent words counted so far, ordered as in TopWithCounts.
+// It keeps only n words in a heap instead of sorting all of them.
+func (c *Counter) Top(n int) []WordCount {
+	if n <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := make(wordHeap, 0, min(n, len(c.counts))+1)
+	for w, count := range c.counts {
+		wc := WordCount{Word: w, Count: count}
+		if len(h) == n && !ranksHigher(wc, h[0]) {
+			continue
+		}
+		heap.Push(&h, wc)
+		if len(h) > n {
+			heap.Pop(&h)
+		}
+	}
+
+	result := make([]WordCount, len(h))
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(&h).(WordCount)
+	}
+	return result
+}
+
+func ranksHigher(a, b WordCount) bool {
+	if a.Count == b.Count {
+		return a.Word < b.Word
+	}
+	return a.Count > b.Count
+}
+
+// wordHeap is a min-heap: its root is the lowest ranked word.
+type wordHeap []WordCount
+
+func (h wordHeap) Len() int           { return len(h) }
+func (h wordHeap) Less(i, j int) bool { return ranksHigher(h[j], h[i]) }
+func (h wordHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *wordHeap) Push(x any) {
+	*h = append(*h, x.(WordCount))
+}
+
+func (h *wordHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounter(t *testing.T) {
+	t.Run("no words in empty reader", func(t *testing.T) {
+		c := NewCounter()
+		require.NoError(t, c.Add(strings.NewReader("")))
+		require.Len(t, c.Top(10), 0)
+	})
+
+	t.Run("matches TopWithCounts", func(t *testing.T) {
+		c := NewCounter()
+		require.NoError(t, c.Add(strings.NewReader(text)))
+		require.Equal(t, TopWithCounts(text, 10), c.Top(10))
+		require.Equal(t, TopWithCounts(text, 1000), c.Top(1000))
+	})
+
+	t.Run("text larger than a chunk", func(t *testing.T) {
+		large := strings.Repeat(text+"\n", 200)
+		c := NewCounter(WithStopWords(Russian))
+		require.NoError(t, c.Add(strings.NewReader(large)))
+		require.Equal(t, TopWithCounts(large, 20, WithStopWords(Russian)), c.Top(20))
+	})
+
+	t.Run("text without newlines", func(t *testing.T) {
+		large := strings.Repeat(strings.ReplaceAll(text, "\n", " ")+" ", 200)
+		c := NewCounter()
+		require.NoError(t, c.Add(strings.NewReader(large)))
+		require.Equal(t, TopWithCounts(large, 20), c.Top(20))
+	})
+
+	t.Run("counts are accumulated across Add calls", func(t *testing.T) {
+		c := NewCounter()
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				require.NoError(t, c.Add(strings.NewReader("cat dog\ncat")))
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, []WordCount{{Word: "cat", Count: 20}, {Word: "dog", Count: 10}}, c.Top(5))
+	})
+
+	t.Run("read error leaves counter unchanged", func(t *testing.T) {
+		errRead := errors.New("read failed")
+		c := NewCounter()
+		require.NoError(t, c.Add(strings.NewReader("cat")))
+		err := c.Add(io.MultiReader(strings.NewReader(text), iotest.ErrReader(errRead)))
+		require.ErrorIs(t, err, errRead)
+		require.Equal(t, []WordCount{{Word: "cat", Count: 1}}, c.Top(10))
+	})
+}
+
+func TestReadChunks(t *testing.T) {
+	read := func(input string) []string {
+		chunks := make(chan string)
+		var result []string
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for chunk := range chunks {
+				result = append(result, chunk)
+			}
+		}()
+		require.NoError(t, readChunks(bufio.NewReaderSize(strings.NewReader(input), chunkSize), chunks))
+		close(chunks)
+		<-done
+		return result
+	}
+
+	t.Run("chunks end at whitespace", func(t *testing.T) {
+		input := strings.Repeat("слово\tword ", 20_000)
+		chunks := read(input)
+		require.Greater(t, len(chunks), 1)
+		for _, chunk := range chunks[:len(chunks)-1] {
+			require.LessOrEqual(t, len(chunk), chunkSize+maxChunkExtension)
+			require.Contains(t, " \t", chunk[len(chunk)-1:])
+		}
+		require.Equal(t, input, strings.Join(chunks, ""))
+	})
+
+	t.Run("text without whitespace is read in bounded chunks", func(t *testing.T) {
+		input := strings.Repeat("ё", 200_000)
+		chunks := read(input)
+		for _, chunk := range chunks {
+			require.LessOrEqual(t, len(chunk), chunkSize+maxChunkExtension+utf8.UTFMax)
+		}
+		require.Equal(t, input, strings.Join(chunks, ""))
+	})
+}
+
+func benchmarkText() string {
+	return strings.Repeat(text+"\n", 2000)
+}
+
+func BenchmarkTopWithCountsSort(b *testing.B) {
+	input := benchmarkText()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TopWithCounts(input, 10)
+	}
+}
+
+func BenchmarkCounterHeap(b *testing.B) {
+	input := benchmarkText()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := NewCounter()
+		if err := c.Add(strings.NewReader(input)); err != nil {
+			b.Fatal(err)
+		}
+		c.Top(10)
+	}
+}
+
+func benchmarkCounts() map[string]int {
+	counts := make(map[string]int, 100_000)
+	for i := 0; i < 100_000; i++ {
+		counts[strconv.Itoa(i)] = i % 997
+	}
+	return counts
+}
+
+func BenchmarkTopSortSlice(b *testing.B) {
+	counts := benchmarkCounts()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sortWords(counts, 10)
+	}
+}
+
+func BenchmarkTopHeap(b *testing.B) {
+	c := NewCounter()
+	c.counts = benchmarkCounts()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Top(10)
+	}
+}
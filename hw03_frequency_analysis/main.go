@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+var cli cliOptions
+
+func init() {
+	flag.IntVar(&cli.n, "n", 10, "number of most frequent words to print")
+	flag.StringVar(&cli.languages, "lang", "", "comma-separated languages (en, ru) whose stop words are skipped")
+	flag.StringVar(&cli.stopWordsFile, "stop-words", "", "file with additional stop words, one per line")
+	flag.BoolVar(&cli.caseSensitive, "case-sensitive", false, `count "Нога" and "нога" as different words`)
+	flag.BoolVar(&cli.stem, "stem", false, "count word forms of -lang languages together")
+	flag.StringVar(&cli.format, "format", formatTable, "output format: table, json or csv")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file ...]\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Prints the most frequent words of the files or of stdin.")
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if err := run(cli, flag.Args(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A word ending with a terminal mark, possibly followed by closing quotes or brackets, ends a sentence.
+var regexSentenceEnd = regexp.MustCompile(`[.!?…]["'»”)\]]*$`)
+
+// TopNGrams returns up to n most frequent phrases of size words, joined with a space.
+// Words pass the same sanitization as in TopWithCounts, and a phrase never
+// crosses a sentence boundary or a dropped word, such as a stop word.
+func TopNGrams(input string, size, n int, opts ...Option) []WordCount {
+	var sortedPhrasesResult []WordCount
+	if input == "" || size <= 0 || n <= 0 {
+		return sortedPhrasesResult
+	}
+	cfg := newConfig(opts)
+	// 0. input string -> slice of words -> sentences
+	sentences := splitSentences(cfg.tokenizer(input))
+	// 1. Count phrases within every run of kept words of a sentence
+	phrasesCount := make(map[string]int)
+	for _, sentence := range sentences {
+		run := make([]string, 0, len(sentence))
+		for _, w := range sentence {
+			if sanitized, ok := sanitizeWord(w, cfg); ok {
+				run = append(run, sanitized)
+				continue
+			}
+			countPhrases(phrasesCount, stemWords(run, cfg), size)
+			run = run[:0]
+		}
+		countPhrases(phrasesCount, stemWords(run, cfg), size)
+	}
+	// 2. Convert a map into a sorted kv slice
+	sortedPhrasesResult = sortWords(phrasesCount, n)
+
+	return sortedPhrasesResult
+}
+
+func countPhrases(phrasesCount map[string]int, words []string, size int) {
+	for i := 0; i+size <= len(words); i++ {
+		phrasesCount[strings.Join(words[i:i+size], " ")]++
+	}
+}
+
+func splitSentences(words []string) [][]string {
+	var sentences [][]string
+	start := 0
+	for i, w := range words {
+		if regexSentenceEnd.MatchString(w) {
+			sentences = append(sentences, words[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(words) {
+		sentences = append(sentences, words[start:])
+	}
+	return sentences
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopNGrams(t *testing.T) {
+	t.Run("no phrases in empty string", func(t *testing.T) {
+		require.Len(t, TopNGrams("", 2, 10), 0)
+		require.Len(t, TopNGrams(text, 0, 10), 0)
+	})
+
+	t.Run("unigrams match TopWithCounts", func(t *testing.T) {
+		require.Equal(t, TopWithCounts(text, 10), TopNGrams(text, 1, 10))
+	})
+
+	t.Run("bigrams", func(t *testing.T) {
+		expected := []WordCount{
+			{Word: "кристофер робин", Count: 4},
+			{Word: "а если", Count: 2},
+			{Word: "вы знаете", Count: 2},
+		}
+		require.Equal(t, expected, TopNGrams(text, 2, 3))
+	})
+
+	t.Run("trigrams", func(t *testing.T) {
+		input := "One, two, three! One two three. ONE TWO three four"
+		expected := []WordCount{
+			{Word: "one two three", Count: 3},
+			{Word: "two three four", Count: 1},
+		}
+		require.Equal(t, expected, TopNGrams(input, 3, 10))
+	})
+
+	t.Run("phrases do not cross sentence boundaries", func(t *testing.T) {
+		input := `Stop. Go! Stop? Go… "Stop." Go (stop.) go`
+		require.Equal(t, []WordCount{{Word: "go stop", Count: 1}}, TopNGrams(input, 2, 10))
+	})
+
+	t.Run("options", func(t *testing.T) {
+		input := "The cat sat on the mat. The cat sat."
+		expected := []WordCount{{Word: "cat sat", Count: 2}}
+		require.Equal(t, expected, TopNGrams(input, 2, 10, WithStopWords(English)))
+	})
+
+	t.Run("dropped words break phrases", func(t *testing.T) {
+		input := "big cat on big mat 42 big cat - big mat"
+		expected := []WordCount{{Word: "big cat", Count: 2}, {Word: "big mat", Count: 2}}
+		require.Equal(t, expected, TopNGrams(input, 2, 10, WithStopWords(English)))
+	})
+}
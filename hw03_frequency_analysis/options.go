@@ -0,0 +1,86 @@
+package main
+
+import "strings"
+
+// Tokenizer splits a text into raw words before sanitization.
+type Tokenizer func(text string) []string
+
+// CaseFolding defines whether "Нога" and "нога" are the same word.
+type CaseFolding int
+
+const (
+	// FoldToLower counts words case-insensitively, reporting them in lower case.
+	FoldToLower CaseFolding = iota
+	// PreserveCase counts "Нога" and "нога" as different words.
+	PreserveCase
+)
+
+type Option func(*config)
+
+type config struct {
+	tokenizer   Tokenizer
+	caseFolding CaseFolding
+	stopWords   map[string]struct{}
+	stemmer     Stemmer
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		tokenizer:   strings.Fields,
+		caseFolding: FoldToLower,
+		stopWords:   make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithTokenizer replaces the default whitespace tokenizer (strings.Fields).
+func WithTokenizer(tokenizer Tokenizer) Option {
+	return func(c *config) {
+		c.tokenizer = tokenizer
+	}
+}
+
+func WithCaseFolding(caseFolding CaseFolding) Option {
+	return func(c *config) {
+		c.caseFolding = caseFolding
+	}
+}
+
+// WithStopWords skips the built-in stop words of the given languages.
+// Unknown languages are ignored.
+func WithStopWords(languages ...Language) Option {
+	return func(c *config) {
+		for _, lang := range languages {
+			addStopWords(c.stopWords, stopWords[lang])
+		}
+	}
+}
+
+// WithCustomStopWords skips the given words.
+func WithCustomStopWords(words ...string) Option {
+	return func(c *config) {
+		addStopWords(c.stopWords, words)
+	}
+}
+
+// Stop words are matched case-insensitively.
+func addStopWords(set map[string]struct{}, words []string) {
+	for _, w := range words {
+		set[strings.ToLower(w)] = struct{}{}
+	}
+}
+
+func (c config) isStopWord(word string) bool {
+	_, ok := c.stopWords[strings.ToLower(word)]
+	return ok
+}
+
+func (c config) fold(word string) string {
+	if c.caseFolding == PreserveCase {
+		return word
+	}
+	return strings.ToLower(word)
+}
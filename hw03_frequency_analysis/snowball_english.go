@@ -0,0 +1,275 @@
+package main
+
+import "strings"
+
+// English Snowball (Porter2) stemmer, see https://snowballstem.org/algorithms/english/stemmer.html.
+
+var (
+	englishExceptions = map[string]string{
+		"skis": "ski", "skies": "sky", "dying": "die", "lying": "lie", "tying": "tie",
+		"idly": "idl", "gently": "gentl", "ugly": "ugli", "early": "earli", "only": "onli",
+		"singly": "singl", "sky": "sky", "news": "news", "howe": "howe",
+		"atlas": "atlas", "cosmos": "cosmos", "bias": "bias", "andes": "andes",
+	}
+	// Words left as they are after step 1a.
+	englishInvariants = map[string]struct{}{
+		"inning": {}, "outing": {}, "canning": {}, "herring": {}, "earring": {},
+		"proceed": {}, "exceed": {}, "succeed": {},
+	}
+	englishR1Prefixes = []string{"gener", "commun", "arsen"}
+
+	englishStep1bSuffixes = []string{"eedly", "ingly", "edly", "eed", "ing", "ed"}
+	englishDoubles        = []string{"bb", "dd", "ff", "gg", "mm", "nn", "pp", "rr", "tt"}
+	englishStep2Suffixes  = map[string]string{
+		"tional": "tion", "enci": "ence", "anci": "ance", "abli": "able", "entli": "ent",
+		"izer": "ize", "ization": "ize", "ational": "ate", "ation": "ate", "ator": "ate",
+		"alism": "al", "aliti": "al", "alli": "al", "fulness": "ful", "ousli": "ous",
+		"ousness": "ous", "iveness": "ive", "iviti": "ive", "biliti": "ble", "bli": "ble",
+		"ogi": "og", "fulli": "ful", "lessli": "less", "li": "",
+	}
+	englishStep3Suffixes = map[string]string{
+		"tional": "tion", "ational": "ate", "alize": "al", "icate": "ic", "iciti": "ic",
+		"ical": "ic", "ful": "", "ness": "", "ative": "",
+	}
+	englishStep4Suffixes = map[string]string{
+		"al": "", "ance": "", "ence": "", "er": "", "ic": "", "able": "", "ible": "", "ant": "",
+		"ement": "", "ment": "", "ent": "", "ism": "", "ate": "", "iti": "", "ous": "",
+		"ive": "", "ize": "", "ion": "",
+	}
+	englishStep2Keys = keys(englishStep2Suffixes)
+	englishStep3Keys = keys(englishStep3Suffixes)
+	englishStep4Keys = keys(englishStep4Suffixes)
+)
+
+// StemEnglish reduces an English word to its stem: "running" => "run", "ponies" => "poni".
+func StemEnglish(word string) string {
+	word = strings.ToLower(word)
+	if len(word) <= 2 {
+		return word
+	}
+	if stem, ok := englishExceptions[word]; ok {
+		return stem
+	}
+
+	s := englishStemmer{word: []byte(word)}
+	s.prelude()
+	s.markRegions()
+	s.step0()
+	s.step1a()
+	if _, ok := englishInvariants[string(s.word)]; !ok {
+		s.step1b()
+		s.step1c()
+		s.step2()
+		s.step3()
+		s.step4()
+		s.step5()
+	}
+	return strings.ReplaceAll(string(s.word), "Y", "y")
+}
+
+type englishStemmer struct {
+	word   []byte
+	p1, p2 int
+}
+
+func isEnglishVowel(char byte) bool {
+	return strings.IndexByte("aeiouy", char) >= 0
+}
+
+// Marks y which acts as a consonant with Y.
+func (s *englishStemmer) prelude() {
+	if s.word[0] == '\'' {
+		s.word = s.word[1:]
+	}
+	for i, char := range s.word {
+		if char == 'y' && (i == 0 || isEnglishVowel(s.word[i-1])) {
+			s.word[i] = 'Y'
+		}
+	}
+}
+
+// R1 starts after the first non-vowel following a vowel, R2 is R1 of R1.
+func (s *englishStemmer) markRegions() {
+	s.p1 = -1
+	for _, prefix := range englishR1Prefixes {
+		if strings.HasPrefix(string(s.word), prefix) {
+			s.p1 = len(prefix)
+		}
+	}
+	if s.p1 < 0 {
+		s.p1 = s.nextRegion(0)
+	}
+	s.p2 = s.nextRegion(s.p1)
+}
+
+func (s *englishStemmer) nextRegion(from int) int {
+	for i := from + 1; i < len(s.word); i++ {
+		if !isEnglishVowel(s.word[i]) && isEnglishVowel(s.word[i-1]) {
+			return i + 1
+		}
+	}
+	return len(s.word)
+}
+
+func (s *englishStemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.word), suffix)
+}
+
+// Returns the longest suffix of the word among the given ones.
+func (s *englishStemmer) longestSuffix(suffixes []string) string {
+	longest := ""
+	for _, suffix := range suffixes {
+		if len(suffix) > len(longest) && s.hasSuffix(suffix) {
+			longest = suffix
+		}
+	}
+	return longest
+}
+
+func (s *englishStemmer) replaceSuffix(suffix, replacement string) {
+	s.word = append(s.word[:len(s.word)-len(suffix)], replacement...)
+}
+
+func (s *englishStemmer) inR1(suffix string) bool {
+	return len(s.word)-len(suffix) >= s.p1
+}
+
+func (s *englishStemmer) inR2(suffix string) bool {
+	return len(s.word)-len(suffix) >= s.p2
+}
+
+// A short syllable is a non-vowel, a vowel and a non-vowel other than w, x or Y,
+// or a vowel followed by a non-vowel at the beginning of the word.
+func (s *englishStemmer) endsWithShortSyllable() bool {
+	w, n := s.word, len(s.word)
+	if n >= 3 && !isEnglishVowel(w[n-3]) && isEnglishVowel(w[n-2]) && !isEnglishVowel(w[n-1]) &&
+		strings.IndexByte("wxY", w[n-1]) < 0 {
+		return true
+	}
+	return n == 2 && isEnglishVowel(w[0]) && !isEnglishVowel(w[1])
+}
+
+func containsEnglishVowel(word []byte) bool {
+	for _, char := range word {
+		if isEnglishVowel(char) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *englishStemmer) step0() {
+	if suffix := s.longestSuffix([]string{"'s'", "'s", "'"}); suffix != "" {
+		s.replaceSuffix(suffix, "")
+	}
+}
+
+func (s *englishStemmer) step1a() {
+	n := len(s.word)
+	switch {
+	case s.hasSuffix("sses"):
+		s.replaceSuffix("sses", "ss")
+	case s.hasSuffix("ied") || s.hasSuffix("ies"):
+		if n > 4 {
+			s.replaceSuffix("ies", "i")
+		} else {
+			s.replaceSuffix("ies", "ie")
+		}
+	case s.hasSuffix("us") || s.hasSuffix("ss"):
+	case s.hasSuffix("s"):
+		// delete if a vowel precedes the letter before s
+		if n > 2 && containsEnglishVowel(s.word[:n-2]) {
+			s.replaceSuffix("s", "")
+		}
+	}
+}
+
+func (s *englishStemmer) step1b() {
+	suffix := s.longestSuffix(englishStep1bSuffixes)
+	switch suffix {
+	case "":
+	case "eed", "eedly":
+		if s.inR1(suffix) {
+			s.replaceSuffix(suffix, "ee")
+		}
+	default:
+		if !containsEnglishVowel(s.word[:len(s.word)-len(suffix)]) {
+			return
+		}
+		s.replaceSuffix(suffix, "")
+		switch {
+		case s.hasSuffix("at") || s.hasSuffix("bl") || s.hasSuffix("iz"):
+			s.word = append(s.word, 'e')
+		case s.longestSuffix(englishDoubles) != "":
+			s.word = s.word[:len(s.word)-1]
+		case s.p1 == len(s.word) && s.endsWithShortSyllable():
+			s.word = append(s.word, 'e')
+		}
+	}
+}
+
+func (s *englishStemmer) step1c() {
+	n := len(s.word)
+	if n > 2 && (s.word[n-1] == 'y' || s.word[n-1] == 'Y') && !isEnglishVowel(s.word[n-2]) {
+		s.word[n-1] = 'i'
+	}
+}
+
+func (s *englishStemmer) step2() {
+	suffix := s.longestSuffix(englishStep2Keys)
+	if suffix == "" || !s.inR1(suffix) {
+		return
+	}
+	before := s.word[:len(s.word)-len(suffix)]
+	switch {
+	case suffix == "ogi" && !strings.HasSuffix(string(before), "l"):
+	case suffix == "li" && (len(before) == 0 || strings.IndexByte("cdeghkmnrt", before[len(before)-1]) < 0):
+	default:
+		s.replaceSuffix(suffix, englishStep2Suffixes[suffix])
+	}
+}
+
+func (s *englishStemmer) step3() {
+	suffix := s.longestSuffix(englishStep3Keys)
+	if suffix == "" || !s.inR1(suffix) || suffix == "ative" && !s.inR2(suffix) {
+		return
+	}
+	s.replaceSuffix(suffix, englishStep3Suffixes[suffix])
+}
+
+func (s *englishStemmer) step4() {
+	suffix := s.longestSuffix(englishStep4Keys)
+	if suffix == "" || !s.inR2(suffix) {
+		return
+	}
+	if suffix == "ion" && !s.hasSuffix("sion") && !s.hasSuffix("tion") {
+		return
+	}
+	s.replaceSuffix(suffix, "")
+}
+
+func (s *englishStemmer) step5() {
+	switch {
+	case s.hasSuffix("e"):
+		if s.inR2("e") {
+			s.replaceSuffix("e", "")
+			return
+		}
+		if s.inR1("e") {
+			s.replaceSuffix("e", "")
+			if s.endsWithShortSyllable() {
+				s.word = append(s.word, 'e')
+			}
+		}
+	case s.hasSuffix("ll") && s.inR2("l"):
+		s.replaceSuffix("l", "")
+	}
+}
+
+func keys(m map[string]string) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Russian Snowball stemmer, see https://snowballstem.org/algorithms/russian/stemmer.html.
+
+// russianEnding is a suffix, which is removed only after "а" or "я" when afterAOrYa is set.
+type russianEnding struct {
+	suffix     string
+	size       int
+	afterAOrYa bool
+}
+
+var (
+	russianPerfectiveGerund = russianEndings(
+		[]string{"в", "вши", "вшись"},
+		[]string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"},
+	)
+	russianAdjective = russianEndings(nil, []string{
+		"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	})
+	russianParticiple = russianEndings(
+		[]string{"ем", "нн", "вш", "ющ", "щ"},
+		[]string{"ивш", "ывш", "ующ"},
+	)
+	russianReflexive = russianEndings(nil, []string{"ся", "сь"})
+	russianVerb      = russianEndings(
+		[]string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"},
+		[]string{
+			"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+			"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю",
+		},
+	)
+	russianNoun = russianEndings(nil, []string{
+		"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий",
+		"й", "иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю",
+		"ия", "ья", "я",
+	})
+	russianI            = russianEndings(nil, []string{"и"})
+	russianDerivational = russianEndings(nil, []string{"ост", "ость"})
+	russianSuperlative  = russianEndings(nil, []string{"ейш", "ейше"})
+)
+
+func russianEndings(afterAOrYa, always []string) []russianEnding {
+	endings := make([]russianEnding, 0, len(afterAOrYa)+len(always))
+	for _, suffix := range afterAOrYa {
+		endings = append(endings, russianEnding{suffix: suffix, size: utf8.RuneCountInString(suffix), afterAOrYa: true})
+	}
+	for _, suffix := range always {
+		endings = append(endings, russianEnding{suffix: suffix, size: utf8.RuneCountInString(suffix)})
+	}
+	return endings
+}
+
+// StemRussian reduces a Russian word to its stem: "ноги" => "ног", "важнейшие" => "важн".
+func StemRussian(word string) string {
+	word = strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+	s := russianStemmer{word: []rune(word)}
+	s.markRegions()
+
+	// Step 1
+	if !s.removeEnding(russianPerfectiveGerund) {
+		s.removeEnding(russianReflexive)
+		switch {
+		case s.removeEnding(russianAdjective):
+			s.removeEnding(russianParticiple)
+		case s.removeEnding(russianVerb):
+		default:
+			s.removeEnding(russianNoun)
+		}
+	}
+	// Step 2
+	s.removeEnding(russianI)
+	// Step 3
+	if ending := s.longestEnding(russianDerivational); ending != nil && len(s.word)-ending.size >= s.p2 {
+		s.word = s.word[:len(s.word)-ending.size]
+	}
+	// Step 4
+	switch {
+	case s.removeEnding(russianSuperlative):
+		s.undoubleN()
+	case s.undoubleN():
+	case s.hasSuffix("ь") && len(s.word)-1 >= s.rv:
+		s.word = s.word[:len(s.word)-1]
+	}
+	return string(s.word)
+}
+
+type russianStemmer struct {
+	word []rune
+	// rv starts after the first vowel, p2 is the standard R2 region
+	rv, p2 int
+}
+
+func isRussianVowel(char rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", char)
+}
+
+func (s *russianStemmer) markRegions() {
+	s.rv, s.p2 = len(s.word), len(s.word)
+	i := 0
+	next := func(vowel bool) bool {
+		for ; i < len(s.word); i++ {
+			if isRussianVowel(s.word[i]) == vowel {
+				i++
+				return true
+			}
+		}
+		return false
+	}
+	if !next(true) {
+		return
+	}
+	s.rv = i
+	if next(false) && next(true) && next(false) {
+		s.p2 = i
+	}
+}
+
+func (s *russianStemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.word), suffix)
+}
+
+// Returns the longest ending found within RV.
+func (s *russianStemmer) longestEnding(endings []russianEnding) *russianEnding {
+	var longest *russianEnding
+	for i := range endings {
+		e := &endings[i]
+		if len(s.word)-e.size < s.rv || !s.hasSuffix(e.suffix) {
+			continue
+		}
+		if longest == nil || e.size > longest.size {
+			longest = e
+		}
+	}
+	return longest
+}
+
+// Removes the longest ending found, unless it must follow "а" or "я" and does not.
+func (s *russianStemmer) removeEnding(endings []russianEnding) bool {
+	ending := s.longestEnding(endings)
+	if ending == nil {
+		return false
+	}
+	start := len(s.word) - ending.size
+	if ending.afterAOrYa && (start-1 < s.rv || s.word[start-1] != 'а' && s.word[start-1] != 'я') {
+		return false
+	}
+	s.word = s.word[:start]
+	return true
+}
+
+func (s *russianStemmer) undoubleN() bool {
+	if s.hasSuffix("нн") && len(s.word)-2 >= s.rv {
+		s.word = s.word[:len(s.word)-1]
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Stemmer reduces a word form to its stem, so that "нога", "ноги" and "ногу" are counted together.
+type Stemmer func(word string) string
+
+// WithStemmer counts words by the stems returned by stemmer.
+func WithStemmer(stemmer Stemmer) Option {
+	return func(c *config) {
+		c.stemmer = stemmer
+	}
+}
+
+// WithStemming counts words by their Snowball stems, e.g. "ног" for "нога", "ноги" and "ногу".
+// A stemmer is picked by the script of a word: Cyrillic for Russian and Latin for English.
+// Words of other languages are counted as they are. Stems are always in lower case.
+func WithStemming(languages ...Language) Option {
+	stemmers := make(map[*unicode.RangeTable]Stemmer)
+	for _, lang := range languages {
+		switch lang {
+		case Russian:
+			stemmers[unicode.Cyrillic] = StemRussian
+		case English:
+			stemmers[unicode.Latin] = StemEnglish
+		}
+	}
+	return WithStemmer(func(word string) string {
+		first, _ := utf8.DecodeRuneInString(word)
+		for script, stem := range stemmers {
+			if unicode.Is(script, first) {
+				return stem(word)
+			}
+		}
+		return word
+	})
+}
+
+func stemWords(words []string, cfg config) []string {
+	if cfg.stemmer == nil {
+		return words
+	}
+	for i, w := range words {
+		words[i] = cfg.stemmer(w)
+	}
+	return words
+}
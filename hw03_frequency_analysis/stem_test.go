@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStemEnglish(t *testing.T) {
+	tests := map[string]string{
+		"a": "a", "cats": "cat", "running": "run", "hoping": "hope", "hopping": "hop",
+		"ponies": "poni", "ties": "tie", "caresses": "caress", "happy": "happi",
+		"agreed": "agre", "feed": "feed", "generously": "generous", "national": "nation",
+		"relational": "relat", "generalization": "general", "consolatory": "consolatori",
+		"conspicuously": "conspicu", "skies": "sky", "news": "news", "proceed": "proceed",
+		"yelling": "yell", "Enjoying": "enjoy", "cry": "cri", "boy's": "boy",
+	}
+	for word, stem := range tests {
+		require.Equalf(t, stem, StemEnglish(word), "stem of %q", word)
+	}
+}
+
+func TestStemRussian(t *testing.T) {
+	tests := map[string]string{
+		"в": "в", "нога": "ног", "ноги": "ног", "ногу": "ног", "вагонов": "вагон",
+		"важнейшие": "важн", "важности": "важност", "важничал": "важнича",
+		"валялась": "валя", "валясь": "вал", "абиссинию": "абиссин", "Ёлки": "елк",
+		"прочитавши": "прочита", "бегущий": "бегущ", "длинный": "длин", "мысль": "мысл",
+	}
+	for word, stem := range tests {
+		require.Equalf(t, stem, StemRussian(word), "stem of %q", word)
+	}
+}
+
+func TestTopWithStemming(t *testing.T) {
+	t.Run("word forms are counted together", func(t *testing.T) {
+		input := "Нога ноги ногу ногой рука руки. Running runs runner run"
+		expected := []WordCount{
+			{Word: "ног", Count: 4},
+			{Word: "run", Count: 3},
+			{Word: "рук", Count: 2},
+			{Word: "runner", Count: 1},
+		}
+		require.Equal(t, expected, TopWithCounts(input, 10, WithStemming(Russian, English)))
+	})
+
+	t.Run("only given languages are stemmed", func(t *testing.T) {
+		input := "ноги ногу runs run"
+		expected := []string{"ног", "run", "runs"}
+		require.Equal(t, expected, TopN(input, 10, WithStemming(Russian)))
+	})
+
+	t.Run("custom stemmer", func(t *testing.T) {
+		trimPlural := func(word string) string {
+			return strings.TrimSuffix(word, "s")
+		}
+		expected := []WordCount{{Word: "cat", Count: 2}, {Word: "dog", Count: 1}}
+		require.Equal(t, expected, TopWithCounts("cats cat dogs", 10, WithStemmer(trimPlural)))
+	})
+}
@@ -0,0 +1,29 @@
+package main
+
+type Language string
+
+const (
+	English Language = "en"
+	Russian Language = "ru"
+)
+
+var stopWords = map[Language][]string{
+	English: {
+		"a", "about", "after", "all", "also", "an", "and", "any", "are", "as", "at",
+		"be", "been", "but", "by", "can", "could", "did", "do", "does", "for", "from",
+		"had", "has", "have", "he", "her", "his", "how", "i", "if", "in", "into", "is",
+		"it", "its", "me", "my", "no", "not", "of", "on", "or", "our", "she", "so",
+		"than", "that", "the", "their", "them", "then", "there", "these", "they",
+		"this", "to", "up", "us", "was", "we", "were", "what", "when", "which", "who",
+		"will", "with", "would", "you", "your",
+	},
+	Russian: {
+		"а", "без", "бы", "был", "была", "были", "было", "быть", "в", "вам", "вас",
+		"во", "вот", "все", "всё", "вы", "да", "для", "до", "его", "ее", "её", "ей",
+		"если", "есть", "еще", "ещё", "же", "за", "и", "из", "или", "им", "их", "к",
+		"как", "когда", "кто", "ли", "мне", "мы", "на", "над", "не", "нет", "ни",
+		"но", "о", "об", "он", "она", "они", "оно", "от", "по", "под", "при", "с",
+		"со", "так", "также", "там", "то", "только", "ты", "у", "уже", "чем", "что",
+		"чтобы", "это", "я",
+	},
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"regexp"
 	"sort"
-	"strings"
 )
 
 var (
@@ -11,80 +10,101 @@ var (
 	onlyLatinOrCyrillicSymbols  = regexp.MustCompile(`^[\p{Latin}\p{Cyrillic}\s]*$`)
 )
 
-type keyValue struct {
-	word  string
-	count int
+// WordCount is a word with the number of its occurrences in a text.
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
 }
 
 func Top10(input string) []string {
-	var sortedWordsResult []string
-	if input == "" {
+	return wordsOf(TopWithCounts(input, 10))
+}
+
+// TopN returns up to n most frequent words, see Option for tuning.
+func TopN(input string, n int, opts ...Option) []string {
+	return wordsOf(TopWithCounts(input, n, opts...))
+}
+
+// TopWithCounts returns up to n most frequent words with their counts,
+// ordered by count and then lexicographically.
+func TopWithCounts(input string, n int, opts ...Option) []WordCount {
+	var sortedWordsResult []WordCount
+	if input == "" || n <= 0 {
 		return sortedWordsResult
 	}
+	cfg := newConfig(opts)
 	// 0. input string -> slice of words
-	words := strings.Fields(input)
+	words := cfg.tokenizer(input)
 	// 1. Sanitize words
-	sanitizedWords := sanititzeWords(words)
+	sanitizedWords := sanititzeWords(words, cfg)
+	// 1.1. Reduce word forms to stems, if enabled
+	stemmedWords := stemWords(sanitizedWords, cfg)
 	// 2. Convert input text into map [word(string) : count(int)]
-	wordsCount := countWords(sanitizedWords)
+	wordsCount := countWords(stemmedWords)
 	// 3. Convert a map into a sorted kv slice
-	sortedWordsResult = sortWords(wordsCount)
-	// 4. topTen := make([]string, 10)
-	if len(sortedWordsResult) == 0 {
-		return sortedWordsResult
-	}
+	sortedWordsResult = sortWords(wordsCount, n)
 
 	return sortedWordsResult
 }
 
-func sortWords(input map[string]int) []string {
+func wordsOf(wordCount []WordCount) []string {
+	words := make([]string, len(wordCount))
+	for i, wc := range wordCount {
+		words[i] = wc.Word
+	}
+	return words
+}
+
+func sortWords(input map[string]int, n int) []WordCount {
 	// 1. Convert map into kv slice
-	wordCount := make([]keyValue, 0, len(input))
+	wordCount := make([]WordCount, 0, len(input))
 	for w, c := range input {
-		wordCount = append(wordCount, keyValue{word: w, count: c})
+		wordCount = append(wordCount, WordCount{Word: w, Count: c})
 	}
 	// 2. Sort kv slice by value
 	sort.Slice(wordCount, func(i, j int) bool {
 		// 2.1. Lexigraphic sort for elements of equal length
-		if wordCount[i].count == wordCount[j].count {
-			return wordCount[i].word < wordCount[j].word
+		if wordCount[i].Count == wordCount[j].Count {
+			return wordCount[i].Word < wordCount[j].Word
 		}
 		// 2.2. Sort by occurrences from highest to lowest
-		return wordCount[i].count > wordCount[j].count
+		return wordCount[i].Count > wordCount[j].Count
 	})
 
-	result := make([]string, 10)
-	for i := 0; i < len(result); i++ {
-		result[i] = wordCount[i].word
-	}
-
-	return result
+	// 3. There may be less than n distinct words
+	return wordCount[:min(n, len(wordCount))]
 }
 
-func sanititzeWords(words []string) (szdWords []string) {
-	szdWords = make([]string, len(words))
-	k := 0
-
+func sanititzeWords(words []string, cfg config) (szdWords []string) {
+	szdWords = make([]string, 0, len(words))
 	for _, w := range words {
-		sanitized := removePunctuation(w)
-		// "-" is not a word
-		if len(sanitized) == 1 && rune(sanitized[0]) == '-' {
-			continue
-		}
-		// empty string is not a word
-		if len(sanitized) == 0 {
-			continue
+		if sanitized, ok := sanitizeWord(w, cfg); ok {
+			szdWords = append(szdWords, sanitized)
 		}
-		// only valid words are counted
-		if !onlyLatinOrCyrillicSymbols.MatchString(sanitized) {
-			continue
-		}
-
-		szdWords[k] = sanitized
-		k++
 	}
+	return szdWords
+}
 
-	return szdWords[:k]
+// sanitizeWord returns the word as it is counted, ok is false if the word is dropped.
+func sanitizeWord(w string, cfg config) (sanitized string, ok bool) {
+	sanitized = cfg.fold(removePunctuation(w))
+	// "-" is not a word
+	if len(sanitized) == 1 && rune(sanitized[0]) == '-' {
+		return "", false
+	}
+	// empty string is not a word
+	if len(sanitized) == 0 {
+		return "", false
+	}
+	// only valid words are counted
+	if !onlyLatinOrCyrillicSymbols.MatchString(sanitized) {
+		return "", false
+	}
+	// stop words are not counted
+	if cfg.isStopWord(sanitized) {
+		return "", false
+	}
+	return sanitized, true
 }
 
 func countWords(words []string) (wordCount map[string]int) {
@@ -98,8 +118,7 @@ func countWords(words []string) (wordCount map[string]int) {
 
 func removePunctuation(input string) string {
 	if len(input) > 1 {
-		noPunctuation := regexRemoveSurroundingPunct.ReplaceAllString(input, "")
-		return strings.ToLower(noPunctuation)
+		return regexRemoveSurroundingPunct.ReplaceAllString(input, "")
 	}
-	return strings.ToLower(input)
+	return input
 }
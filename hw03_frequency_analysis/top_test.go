@@ -1,7 +1,9 @@
 package main
 
 import (
+	"strings"
 	"testing"
+	"unicode"
 
 	"github.com/stretchr/testify/require"
 )
@@ -48,6 +50,11 @@ func TestTop10(t *testing.T) {
 		require.Len(t, Top10(""), 0)
 	})
 
+	t.Run("less than 10 distinct words", func(t *testing.T) {
+		require.Equal(t, []string{"dog", "cat"}, Top10("cat dog Dog"))
+		require.Equal(t, []string{}, Top10("- 42 ..."))
+	})
+
 	t.Run("positive test", func(t *testing.T) {
 		if taskWithAsteriskIsCompleted {
 			expected := []string{
@@ -95,3 +102,83 @@ func TestTop10(t *testing.T) {
 		require.Equal(t, expected, Top10(input))
 	})
 }
+
+func TestTopN(t *testing.T) {
+	t.Run("no words for non-positive n", func(t *testing.T) {
+		require.Len(t, TopN(text, 0), 0)
+	})
+
+	t.Run("default options match Top10", func(t *testing.T) {
+		require.Equal(t, Top10(text), TopN(text, 10))
+		require.Equal(t, Top10(text)[:3], TopN(text, 3))
+	})
+
+	t.Run("stop words", func(t *testing.T) {
+		expected := []string{
+			"кристофер", // 4
+			"робин",     // 4
+			"имя",       // 3
+			"иногда",    // 3
+		}
+		require.Equal(t, expected, TopN(text, 4, WithStopWords(Russian), WithCustomStopWords("ЕМУ")))
+	})
+
+	t.Run("stop words of several languages", func(t *testing.T) {
+		input := "The cat and the dog и кот и пёс, a cat"
+		expected := []string{"cat", "dog", "кот", "пёс"}
+		require.Equal(t, expected, TopN(input, 10, WithStopWords(English, Russian)))
+	})
+
+	t.Run("preserve case", func(t *testing.T) {
+		input := "Нога нога, НОГА! нога рука Рука"
+		expected := []string{"нога", "НОГА", "Нога", "Рука", "рука"}
+		require.Equal(t, expected, TopN(input, 10, WithCaseFolding(PreserveCase)))
+		require.Equal(t, []string{"нога", "рука"}, TopN(input, 10))
+	})
+
+	t.Run("custom tokenizer", func(t *testing.T) {
+		input := "dog,cat;dog cat|dog"
+		splitOnPunct := func(text string) []string {
+			return strings.FieldsFunc(text, func(r rune) bool {
+				return unicode.IsPunct(r) || unicode.IsSpace(r) || r == '|'
+			})
+		}
+		require.Equal(t, []string{"dog", "cat"}, TopN(input, 10, WithTokenizer(splitOnPunct)))
+		require.Empty(t, TopN(input, 10))
+	})
+}
+
+func TestTopWithCounts(t *testing.T) {
+	t.Run("no words in empty string", func(t *testing.T) {
+		require.Len(t, TopWithCounts("", 10), 0)
+	})
+
+	t.Run("counts", func(t *testing.T) {
+		expected := []WordCount{
+			{Word: "а", Count: 8},
+			{Word: "он", Count: 8},
+			{Word: "и", Count: 6},
+			{Word: "ты", Count: 5},
+			{Word: "что", Count: 5},
+		}
+		require.Equal(t, expected, TopWithCounts(text, 5))
+	})
+
+	t.Run("ties are sorted lexicographically", func(t *testing.T) {
+		input := "cat and dog, one dog,two cats and one man"
+		expected := []WordCount{
+			{Word: "and", Count: 2},
+			{Word: "one", Count: 2},
+			{Word: "cat", Count: 1},
+			{Word: "cats", Count: 1},
+			{Word: "dog", Count: 1},
+			{Word: "man", Count: 1},
+		}
+		require.Equal(t, expected, TopWithCounts(input, 100))
+	})
+
+	t.Run("options", func(t *testing.T) {
+		expected := []WordCount{{Word: "cat", Count: 2}, {Word: "dog", Count: 1}}
+		require.Equal(t, expected, TopWithCounts("The cat, the dog, a cat", 10, WithStopWords(English)))
+	})
+}
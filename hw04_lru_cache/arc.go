@@ -0,0 +1,110 @@
+package hw04lrucache
+
+// Политика ARC (Megiddo, Modha, 2003): recent хранит элементы, к которым обращались один раз,
+// frequent - повторно. Для каждой очереди запоминается история вытесненных ключей.
+// Повторный запрос ключа из истории recent увеличивает целевую долю target очереди recent,
+// из истории frequent - уменьшает её. Размеры считаются по стоимости элементов.
+type arcPolicy[K comparable, V any] struct {
+	recent, frequent             entryList[K, V]
+	recentGhosts, frequentGhosts ghostList[K]
+	target                       int
+
+	// следующий добавляемый элемент был в истории и попадёт в frequent
+	promote bool
+	// он был в истории frequent, это учитывается при выборе вытесняемого элемента
+	fromFrequentGhosts bool
+}
+
+func newARCPolicy[K comparable, V any]() *arcPolicy[K, V] {
+	return &arcPolicy[K, V]{
+		recent:         newEntryList[K, V](),
+		frequent:       newEntryList[K, V](),
+		recentGhosts:   newGhostList[K](),
+		frequentGhosts: newGhostList[K](),
+	}
+}
+
+func (p *arcPolicy[K, V]) admit(key K, cost, capacity int) {
+	p.promote, p.fromFrequentGhosts = false, false
+	if ghostCost, ok := p.recentGhosts.remove(key); ok {
+		step := ghostCost * max(p.frequentGhosts.len()/max(p.recentGhosts.len(), 1), 1)
+		p.target = min(p.target+step, capacity)
+		p.promote = true
+		return
+	}
+	if ghostCost, ok := p.frequentGhosts.remove(key); ok {
+		step := ghostCost * max(p.recentGhosts.len()/max(p.frequentGhosts.len(), 1), 1)
+		p.target = max(p.target-step, 0)
+		p.promote, p.fromFrequentGhosts = true, true
+		return
+	}
+	// новый ключ: освободить место в истории
+	p.trimGhosts(cost, capacity)
+}
+
+func (p *arcPolicy[K, V]) add(e *cacheEntry[K, V]) {
+	if p.promote {
+		p.frequent.pushFront(e, segmentFrequent)
+	} else {
+		p.recent.pushFront(e, segmentRecent)
+	}
+	p.promote, p.fromFrequentGhosts = false, false
+}
+
+func (p *arcPolicy[K, V]) hit(e *cacheEntry[K, V], cost int) {
+	p.list(e).setCost(e, cost)
+	if e.segment == segmentFrequent {
+		p.frequent.entries.MoveToFront(e.elem)
+		return
+	}
+	p.recent.remove(e)
+	p.frequent.pushFront(e, segmentFrequent)
+}
+
+func (p *arcPolicy[K, V]) remove(e *cacheEntry[K, V]) {
+	p.list(e).remove(e)
+}
+
+func (p *arcPolicy[K, V]) evict(capacity int) *cacheEntry[K, V] {
+	recentOverTarget := p.recent.cost > p.target || p.fromFrequentGhosts && p.recent.cost == p.target
+	if p.recent.entries.Len() > 0 && (recentOverTarget || p.frequent.entries.Len() == 0) {
+		e := p.recent.back()
+		p.recent.remove(e)
+		p.recentGhosts.push(e.key, e.cost)
+		p.trimGhosts(0, capacity)
+		return e
+	}
+	e := p.frequent.back()
+	p.frequent.remove(e)
+	p.frequentGhosts.push(e.key, e.cost)
+	p.trimGhosts(0, capacity)
+	return e
+}
+
+// История recent вместе с самой очередью не превышает ёмкость,
+// а все очереди и история вместе - двойную ёмкость; extra - стоимость добавляемого элемента.
+func (p *arcPolicy[K, V]) trimGhosts(extra, capacity int) {
+	for p.recentGhosts.len() > 0 && p.recent.cost+p.recentGhosts.cost+extra > capacity {
+		p.recentGhosts.removeOldest()
+	}
+	for p.frequentGhosts.len() > 0 &&
+		p.recent.cost+p.frequent.cost+p.recentGhosts.cost+p.frequentGhosts.cost+extra > 2*capacity {
+		p.frequentGhosts.removeOldest()
+	}
+}
+
+func (p *arcPolicy[K, V]) each(fn func(e *cacheEntry[K, V])) {
+	p.recent.each(fn)
+	p.frequent.each(fn)
+}
+
+func (p *arcPolicy[K, V]) clear() {
+	*p = *newARCPolicy[K, V]()
+}
+
+func (p *arcPolicy[K, V]) list(e *cacheEntry[K, V]) *entryList[K, V] {
+	if e.segment == segmentFrequent {
+		return &p.frequent
+	}
+	return &p.recent
+}
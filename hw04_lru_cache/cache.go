@@ -1,95 +1,488 @@
 package hw04lrucache
 
 import (
+	"io"
+	"slices"
 	"sync"
+	"time"
 )
 
 type Key string
 
-type Cache interface {
-	Set(key Key, value interface{}) bool
-	Get(key Key) (interface{}, bool)
-	Clear()
-}
+// Кэш строковых ключей и значений произвольного типа, как и прежде возвращаемый NewCache.
+type Cache = CacheOf[Key, interface{}]
 
+// Пара ключ-значение кэша строковых ключей, оставлена для совместимости.
 type KeyValue struct {
-	Key   Key // храним в списке так же ключ элемента в словаре для быстрого удаления из словаря
+	Key   Key
 	Value interface{}
 }
 
-type lruCache struct {
-	capacity int
-	mu       sync.Mutex
-	queue    List
-	items    map[Key]*ListItem
+type CacheOf[K comparable, V any] interface {
+	Set(key K, value V) bool
+	// Добавить значение со своим временем жизни, ttl <= 0 - без ограничения.
+	SetWithTTL(key K, value V, ttl time.Duration) bool
+	Get(key K) (V, bool)
+	// Прочитать значение, не меняя порядок вытеснения и статистику.
+	Peek(key K) (V, bool)
+	Contains(key K) bool
+	// Вернуть значение из кэша, а при его отсутствии вызвать loader и сохранить результат.
+	// Ошибка loader возвращается как есть, в кэш ничего не добавляется.
+	GetOrSet(key K, loader func() (V, error)) (V, error)
+	// return = флаг, присутствовал ли элемент в кэше.
+	Delete(key K) bool
+	// Ключи от наиболее ценных для политики вытеснения к кандидатам на вытеснение,
+	// для LRU - от недавно использованных к давно использованным.
+	Keys() []K
+	// Число неустаревших элементов, то есть длина Keys. Если в кэше есть элементы
+	// со временем жизни, Len просматривает все элементы под блокировкой кэша.
+	Len() int
+	// Изменить ёмкость, вытеснив лишние элементы.
+	// return = число вытесненных элементов.
+	Resize(capacity int) int
+	Clear()
+	// Остановить фоновую очистку, если она запущена.
+	Close()
+	// Функция, вызываемая для каждого удалённого из кэша элемента.
+	OnEvict(fn func(key K, value V, reason EvictionReason))
+	Stats() Stats
+	// Записать снимок кэша кодеком из WithCodec и восстановить кэш из снимка.
+	SaveTo(w io.Writer) error
+	LoadFrom(r io.Reader) error
+}
+
+// Причина удаления элемента из кэша.
+type EvictionReason int
+
+const (
+	EvictedByCapacity EvictionReason = iota // вытеснен из-за ёмкости
+	EvictedByTTL                            // устарел
+	EvictedByDelete                         // удалён явно
+	EvictedByClear                          // кэш очищен
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictedByCapacity:
+		return "capacity"
+	case EvictedByTTL:
+		return "ttl"
+	case EvictedByDelete:
+		return "delete"
+	case EvictedByClear:
+		return "clear"
+	}
+	return "unknown"
+}
+
+// Статистика кэша.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	// Число элементов, вытесненных самим кэшем: по ёмкости или по времени жизни.
+	Evictions uint64
+	// Число хранимых элементов, включая устаревшие, которые ещё не удалены.
+	// Stats не просматривает элементы, поэтому Size может быть больше Len.
+	Size int
+	// Суммарная стоимость хранимых элементов, для кэша без функции стоимости равна Size.
+	Cost int
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key   K // храним в списке так же ключ элемента в словаре для быстрого удаления из словаря
+	value V
+	cost  int
+	// нулевое время - элемент не устаревает
+	expiresAt time.Time
+	// время последнего обращения, по нему объединяются ключи сегментов сегментированного кэша
+	used time.Time
+
+	// положение элемента в очередях политики вытеснения
+	elem    *ListItemOf[*cacheEntry[K, V]]
+	segment segment
+	bucket  *ListItemOf[*lfuBucket[K, V]]
+}
+
+func (e *cacheEntry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
 }
 
+type lruCache[K comparable, V any] struct {
+	// ёмкость - предельная суммарная стоимость элементов
+	capacity  int
+	cost      func(key K, value V) int
+	totalCost int
+	// число элементов со временем жизни: пока их нет, Len не просматривает элементы
+	expiring   int
+	defaultTTL time.Duration
+	now        func() time.Time
+	codec      Codec
+	mu         sync.Mutex
+	policy     evictionPolicy[K, V]
+	items      map[K]*cacheEntry[K, V]
+
+	janitor *janitor
+
+	stats   Stats
+	onEvict func(key K, value V, reason EvictionReason)
+	// удалённые под блокировкой элементы, о которых нужно сообщить onEvict
+	evicted []evictedEntry[K, V]
+}
+
+type evictedEntry[K comparable, V any] struct {
+	entry  cacheEntry[K, V]
+	reason EvictionReason
+}
+
+// Кэш строковых ключей и значений произвольного типа.
 func NewCache(capacity int) Cache {
-	return &lruCache{
-		capacity: capacity,
-		queue:    NewList(),
-		items:    make(map[Key]*ListItem, capacity),
+	return NewCacheOf[Key, interface{}](capacity)
+}
+
+// Кэш, ограниченный суммарной стоимостью элементов, а не их числом:
+// например, cost может возвращать размер значения в байтах.
+// Стоимость вычисляется при добавлении элемента и должна быть неотрицательной.
+// Элемент дороже maxCost в кэш не попадает.
+func NewWeightedCache[K comparable, V any](maxCost int, cost func(key K, value V) int, opts ...Option) CacheOf[K, V] {
+	o := newOptions(opts)
+	lc := newLRUCache[K, V](maxCost, cost, o)
+	if o.janitorInterval > 0 {
+		lc.janitor = startJanitor(o.janitorCtx, o.janitorInterval, lc.removeExpired)
+	}
+	return lc
+}
+
+// Типизированный кэш: Get возвращает значение без приведения типа.
+func NewCacheOf[K comparable, V any](capacity int, opts ...Option) CacheOf[K, V] {
+	o := newOptions(opts)
+	lc := newLRUCache[K, V](capacity, nil, o)
+	if o.janitorInterval > 0 {
+		lc.janitor = startJanitor(o.janitorCtx, o.janitorInterval, lc.removeExpired)
+	}
+	return lc
+}
+
+// cost == nil - каждый элемент стоит 1, то есть ёмкость ограничивает число элементов.
+func newLRUCache[K comparable, V any](capacity int, cost func(K, V) int, o options) *lruCache[K, V] {
+	sizeHint := capacity
+	if cost == nil {
+		cost = func(K, V) int { return 1 }
+	} else {
+		// по стоимости нельзя предсказать число элементов
+		sizeHint = 0
+	}
+	return &lruCache[K, V]{
+		capacity:   capacity,
+		cost:       cost,
+		defaultTTL: o.defaultTTL,
+		now:        o.now,
+		codec:      o.codec,
+		policy:     newPolicy[K, V](o.policy),
+		items:      make(map[K]*cacheEntry[K, V], sizeHint),
 	}
 }
 
-// Добавить элемент в кэш
+// Добавить элемент в кэш со временем жизни по умолчанию
 // return = флаг, присутствовал ли элемент в кэше.
-func (lc *lruCache) Set(key Key, value interface{}) bool {
+func (lc *lruCache[K, V]) Set(key K, value V) bool {
+	return lc.SetWithTTL(key, value, lc.defaultTTL)
+}
+
+func (lc *lruCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
 	lc.mu.Lock()
-	defer lc.mu.Unlock()
+	defer lc.unlockAndNotify()
+	return lc.set(key, value, ttl)
+}
 
-	item, exists := lc.items[key]
+func (lc *lruCache[K, V]) set(key K, value V, ttl time.Duration) bool {
+	now := lc.now()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
 
-	//  если элемента нет в словаре, то добавить в словарь и в начало очереди
-	// (если превышена ёмкость кэша, то удалить последний элемент из очереди и из словаря);
-	if !exists {
-		if lc.queue.Len() == lc.capacity {
-			lc.removeLastElement()
+	entry, exists := lc.items[key]
+	// устаревший элемент считается отсутствующим
+	if exists && entry.expired(now) {
+		lc.removeElement(entry, EvictedByTTL)
+		exists = false
+	}
+
+	cost := lc.cost(key, value)
+	// элемент, который не поместится даже в пустой кэш, не добавляется, а прежнее значение удаляется
+	if cost > lc.capacity {
+		if exists {
+			lc.removeElement(entry, EvictedByCapacity)
 		}
-		lc.items[key] = lc.queue.PushFront(KeyValue{Key: key, Value: value})
+		return exists
 	}
 
-	// если элемент присутствует в словаре, то обновить его значение и переместить элемент в начало очереди
+	// если элемент присутствует в словаре, то обновить его значение и считать запись обращением к нему;
+	// если новая стоимость не помещается, вытесняются элементы по политике
 	if exists {
-		item.Value = KeyValue{Key: key, Value: value}
-		lc.queue.MoveToFront(item)
+		lc.totalCost += cost - entry.cost
+		lc.expiring += expiringDelta(entry.expiresAt, expiresAt)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		lc.policy.hit(entry, cost)
+		entry.used = now
+		lc.shrink()
 		return true
 	}
 
+	// если элемента нет в словаре, то освободить под него место и добавить в словарь и в политику
+	lc.policy.admit(key, cost, lc.capacity)
+	for lc.totalCost+cost > lc.capacity && len(lc.items) > 0 {
+		lc.evictElement()
+	}
+	entry = &cacheEntry[K, V]{key: key, value: value, cost: cost, expiresAt: expiresAt}
+	entry.used = now
+	lc.policy.add(entry)
+	lc.items[key] = entry
+	lc.totalCost += cost
+	lc.expiring += expiringDelta(time.Time{}, expiresAt)
+
 	return false
 }
 
-func (lc *lruCache) Get(key Key) (interface{}, bool) {
+func (lc *lruCache[K, V]) Get(key K) (V, bool) {
 	lc.mu.Lock()
-	defer lc.mu.Unlock()
-	// если элемент присутствует в словаре, то переместить элемент в начало очереди и вернуть его значение и true;
-	item, exists := lc.items[key]
+	defer lc.unlockAndNotify()
+	return lc.get(key)
+}
 
+func (lc *lruCache[K, V]) get(key K) (V, bool) {
+	// если элемент присутствует в словаре, то сообщить политике об обращении и вернуть его значение и true;
+	entry, exists := lc.items[key]
+
+	if now := lc.now(); exists && !entry.expired(now) {
+		lc.stats.Hits++
+		lc.policy.hit(entry, entry.cost)
+		entry.used = now
+		return entry.value, true
+	}
+	lc.stats.Misses++
+	// устаревший элемент удаляется сразу
 	if exists {
-		lc.queue.MoveToFront(item)
-		kv, ok := item.Value.(KeyValue)
-		if ok {
-			return kv.Value, true
-		}
+		lc.removeElement(entry, EvictedByTTL)
 	}
-	// если элемента нет в словаре, то вернуть nil и false.
-	return nil, false
+	// если элемента нет в словаре, то вернуть нулевое значение и false.
+	var zero V
+	return zero, false
 }
 
-func (lc *lruCache) Clear() {
+func (lc *lruCache[K, V]) Peek(key K) (V, bool) {
 	lc.mu.Lock()
 	defer lc.mu.Unlock()
-	lc.items = make(map[Key]*ListItem, lc.capacity)
-	lc.queue = NewList()
+	if entry := lc.lookup(key); entry != nil {
+		return entry.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (lc *lruCache[K, V]) Contains(key K) bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.lookup(key) != nil
+}
+
+// Найти неустаревший элемент, ничего не меняя в кэше.
+func (lc *lruCache[K, V]) lookup(key K) *cacheEntry[K, V] {
+	entry, exists := lc.items[key]
+	if !exists || entry.expired(lc.now()) {
+		return nil
+	}
+	return entry
 }
 
-// Удалить последний элемент из очереди и из словаря.
-func (lc *lruCache) removeLastElement() {
-	ListItem := lc.queue.Back()
-	lc.queue.Remove(ListItem)
-	// Найти в словаре ключ для удаления по значению.
-	kv, ok := ListItem.Value.(KeyValue)
+// loader вызывается без блокировки, поэтому параллельные вызовы для одного ключа
+// могут загрузить значение несколько раз; в кэше останется первое сохранённое.
+func (lc *lruCache[K, V]) GetOrSet(key K, loader func() (V, error)) (V, error) {
+	lc.mu.Lock()
+	value, ok := lc.get(key)
+	lc.unlockAndNotify()
 	if ok {
-		delete(lc.items, kv.Key)
+		return value, nil
+	}
+
+	value, err := loader()
+	if err != nil {
+		return value, err
+	}
+
+	lc.mu.Lock()
+	defer lc.unlockAndNotify()
+	if entry := lc.lookup(key); entry != nil {
+		lc.policy.hit(entry, entry.cost)
+		entry.used = lc.now()
+		return entry.value, nil
+	}
+	lc.set(key, value, lc.defaultTTL)
+	return value, nil
+}
+
+func (lc *lruCache[K, V]) Delete(key K) bool {
+	lc.mu.Lock()
+	defer lc.unlockAndNotify()
+	entry, exists := lc.items[key]
+	if !exists {
+		return false
 	}
+	// устаревший элемент удаляется, но считается отсутствующим
+	if entry.expired(lc.now()) {
+		lc.removeElement(entry, EvictedByTTL)
+		return false
+	}
+	lc.removeElement(entry, EvictedByDelete)
+	return true
+}
+
+func (lc *lruCache[K, V]) Keys() []K {
+	return liveEntries(lc, func(e *cacheEntry[K, V]) K { return e.key })
+}
+
+// Значения fn для неустаревших элементов в порядке Keys, fn вызывается под блокировкой.
+func liveEntries[K comparable, V, T any](lc *lruCache[K, V], fn func(e *cacheEntry[K, V]) T) []T {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	now := lc.now()
+	result := make([]T, 0, len(lc.items))
+	lc.policy.each(func(e *cacheEntry[K, V]) {
+		if !e.expired(now) {
+			result = append(result, fn(e))
+		}
+	})
+	slices.Reverse(result)
+	return result
+}
+
+func (lc *lruCache[K, V]) Len() int {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.expiring == 0 {
+		return len(lc.items)
+	}
+	now := lc.now()
+	n := 0
+	for _, e := range lc.items {
+		if !e.expired(now) {
+			n++
+		}
+	}
+	return n
+}
+
+func (lc *lruCache[K, V]) Resize(capacity int) int {
+	lc.mu.Lock()
+	defer lc.unlockAndNotify()
+	lc.capacity = capacity
+	return lc.shrink()
+}
+
+// Вытеснять элементы по политике, пока их стоимость превышает ёмкость.
+// return = число вытесненных элементов.
+func (lc *lruCache[K, V]) shrink() int {
+	evicted := 0
+	for lc.totalCost > lc.capacity && len(lc.items) > 0 {
+		lc.evictElement()
+		evicted++
+	}
+	return evicted
+}
+
+func (lc *lruCache[K, V]) Clear() {
+	lc.mu.Lock()
+	defer lc.unlockAndNotify()
+	if lc.onEvict != nil {
+		lc.policy.each(func(e *cacheEntry[K, V]) {
+			lc.evicted = append(lc.evicted, evictedEntry[K, V]{entry: *e, reason: EvictedByClear})
+		})
+	}
+	lc.items = make(map[K]*cacheEntry[K, V], len(lc.items))
+	lc.policy.clear()
+	lc.totalCost = 0
+	lc.expiring = 0
+}
+
+// Вытеснить элемент, выбранный политикой, из очереди и из словаря.
+func (lc *lruCache[K, V]) evictElement() {
+	lc.forget(lc.policy.evict(lc.capacity), EvictedByCapacity)
+}
+
+func (lc *lruCache[K, V]) removeElement(entry *cacheEntry[K, V], reason EvictionReason) {
+	lc.policy.remove(entry)
+	lc.forget(entry, reason)
+}
+
+// Изменение числа элементов со временем жизни при смене времени устаревания с old на updated.
+func expiringDelta(old, updated time.Time) int {
+	delta := 0
+	if !old.IsZero() {
+		delta--
+	}
+	if !updated.IsZero() {
+		delta++
+	}
+	return delta
+}
+
+// Удалить уже исключённый из политики элемент из словаря.
+func (lc *lruCache[K, V]) forget(entry *cacheEntry[K, V], reason EvictionReason) {
+	delete(lc.items, entry.key)
+	lc.totalCost -= entry.cost
+	lc.expiring += expiringDelta(entry.expiresAt, time.Time{})
+	if reason == EvictedByCapacity || reason == EvictedByTTL {
+		lc.stats.Evictions++
+	}
+	if lc.onEvict != nil {
+		lc.evicted = append(lc.evicted, evictedEntry[K, V]{entry: *entry, reason: reason})
+	}
+}
+
+// Снять блокировку и только затем вызвать onEvict, чтобы он мог обращаться к кэшу.
+func (lc *lruCache[K, V]) unlockAndNotify() {
+	evicted, onEvict := lc.evicted, lc.onEvict
+	lc.evicted = nil
+	lc.mu.Unlock()
+
+	for _, e := range evicted {
+		onEvict(e.entry.key, e.entry.value, e.reason)
+	}
+}
+
+func (lc *lruCache[K, V]) OnEvict(fn func(key K, value V, reason EvictionReason)) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.onEvict = fn
+}
+
+func (lc *lruCache[K, V]) Stats() Stats {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	stats := lc.stats
+	stats.Size = len(lc.items)
+	stats.Cost = lc.totalCost
+	return stats
+}
+
+// Удалить все устаревшие элементы.
+func (lc *lruCache[K, V]) removeExpired() {
+	lc.mu.Lock()
+	defer lc.unlockAndNotify()
+
+	now := lc.now()
+	var expired []*cacheEntry[K, V]
+	lc.policy.each(func(e *cacheEntry[K, V]) {
+		if e.expired(now) {
+			expired = append(expired, e)
+		}
+	})
+	for _, e := range expired {
+		lc.removeElement(e, EvictedByTTL)
+	}
+}
+
+func (lc *lruCache[K, V]) Close() {
+	lc.janitor.close()
 }
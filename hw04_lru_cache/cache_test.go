@@ -1,10 +1,13 @@
 package hw04lrucache
 
 import (
+	"context"
+	"errors"
 	"math/rand"
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -20,6 +23,14 @@ func TestCache(t *testing.T) {
 		require.False(t, ok)
 	})
 
+	t.Run("non-generic type", func(t *testing.T) {
+		var c Cache = NewCache(1)
+		c.Set("aaa", 100)
+		val, ok := c.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 100, val)
+	})
+
 	t.Run("simple", func(t *testing.T) {
 		c := NewCache(5)
 
@@ -92,6 +103,398 @@ func TestCache(t *testing.T) {
 	})
 }
 
+func TestCacheOf(t *testing.T) {
+	t.Run("typed values", func(t *testing.T) {
+		c := NewCacheOf[int, string](2)
+
+		val, ok := c.Get(1)
+		require.False(t, ok)
+		require.Equal(t, "", val)
+
+		require.False(t, c.Set(1, "one"))
+		require.False(t, c.Set(2, "two"))
+		require.True(t, c.Set(1, "один"))
+
+		// 2 использовался наиболее давно
+		require.False(t, c.Set(3, "three"))
+		_, ok = c.Get(2)
+		require.False(t, ok)
+
+		val, ok = c.Get(1)
+		require.True(t, ok)
+		require.Equal(t, "один", val)
+	})
+
+	t.Run("capacity of one", func(t *testing.T) {
+		c := NewCacheOf[string, int](1)
+		require.False(t, c.Set("a", 1))
+		require.True(t, c.Set("a", 2))
+		require.False(t, c.Set("b", 3))
+
+		_, ok := c.Get("a")
+		require.False(t, ok)
+		val, ok := c.Get("b")
+		require.True(t, ok)
+		require.Equal(t, 3, val)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		c := NewCacheOf[string, int](3)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Clear()
+
+		_, ok := c.Get("a")
+		require.False(t, ok)
+		require.False(t, c.Set("a", 3))
+	})
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCacheTTL(t *testing.T) {
+	t.Run("default ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheOf[string, int](5, WithDefaultTTL(time.Minute), withClock(clock.Now))
+		c.Set("a", 1)
+
+		clock.Advance(59 * time.Second)
+		val, ok := c.Get("a")
+		require.True(t, ok)
+		require.Equal(t, 1, val)
+
+		clock.Advance(time.Second)
+		_, ok = c.Get("a")
+		require.False(t, ok)
+	})
+
+	t.Run("per-entry ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheOf[string, int](5, WithDefaultTTL(time.Minute), withClock(clock.Now))
+		c.SetWithTTL("short", 1, time.Second)
+		c.SetWithTTL("forever", 2, 0)
+		c.Set("default", 3)
+
+		clock.Advance(time.Second)
+		_, ok := c.Get("short")
+		require.False(t, ok)
+		_, ok = c.Get("default")
+		require.True(t, ok)
+
+		clock.Advance(time.Hour)
+		_, ok = c.Get("default")
+		require.False(t, ok)
+		_, ok = c.Get("forever")
+		require.True(t, ok)
+	})
+
+	t.Run("expired entry is not reported as present", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheOf[string, int](5, withClock(clock.Now))
+		c.SetWithTTL("a", 1, time.Second)
+
+		clock.Advance(time.Second)
+		require.False(t, c.SetWithTTL("a", 2, time.Second))
+		require.True(t, c.Set("a", 3))
+
+		clock.Advance(time.Hour)
+		val, ok := c.Get("a")
+		require.True(t, ok)
+		require.Equal(t, 3, val)
+	})
+
+	t.Run("janitor removes expired entries", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheOf[string, int](5, withClock(clock.Now), WithJanitor(context.Background(), time.Millisecond))
+		defer c.Close()
+		c.SetWithTTL("a", 1, time.Second)
+		c.SetWithTTL("b", 2, time.Second)
+		c.SetWithTTL("c", 3, time.Hour)
+
+		clock.Advance(time.Minute)
+		lc := c.(*lruCache[string, int])
+		require.Eventually(t, func() bool {
+			lc.mu.Lock()
+			defer lc.mu.Unlock()
+			return lc.policy.(*lruPolicy[string, int]).queue.entries.Len() == 1 && len(lc.items) == 1
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("janitor stops on Close and on context cancel", func(t *testing.T) {
+		c := NewCacheOf[string, int](5, WithJanitor(context.Background(), time.Millisecond))
+		c.Close()
+		c.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c = NewCacheOf[string, int](5, WithJanitor(ctx, time.Millisecond))
+		cancel()
+		c.Close()
+	})
+}
+
+type eviction struct {
+	key    string
+	value  int
+	reason EvictionReason
+}
+
+func TestCacheOnEvict(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheOf[string, int](2, withClock(clock.Now))
+	var evicted []eviction
+	c.OnEvict(func(key string, value int, reason EvictionReason) {
+		evicted = append(evicted, eviction{key, value, reason})
+		// обработчик вызывается без блокировки и может обращаться к кэшу
+		c.Stats()
+	})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("a", 10) // обновление значения не является вытеснением
+	c.Set("c", 3)  // вытесняет b
+	c.SetWithTTL("a", 11, time.Second)
+	clock.Advance(time.Second)
+	c.Get("a") // устарел
+	c.Set("d", 4)
+	c.Clear()
+
+	expected := []eviction{
+		{"b", 2, EvictedByCapacity},
+		{"a", 11, EvictedByTTL},
+		{"c", 3, EvictedByClear},
+		{"d", 4, EvictedByClear},
+	}
+	require.Equal(t, expected, evicted)
+	require.Equal(t, "ttl", EvictedByTTL.String())
+}
+
+func TestCacheStats(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheOf[string, int](2, withClock(clock.Now))
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("a")
+	c.Get("x")
+	c.Set("c", 3) // вытесняет b
+	c.SetWithTTL("d", 4, time.Second)
+	clock.Advance(time.Second)
+	c.Get("d")
+
+	require.Equal(t, Stats{Hits: 2, Misses: 2, Evictions: 3, Size: 1, Cost: 1}, c.Stats())
+
+	c.Clear()
+	require.Equal(t, Stats{Hits: 2, Misses: 2, Evictions: 3, Size: 0}, c.Stats())
+
+	// устаревший, но ещё не удалённый элемент учитывается в Size и Cost, но не в Len
+	c.SetWithTTL("e", 5, time.Second)
+	c.Set("f", 6)
+	clock.Advance(time.Second)
+	require.Equal(t, 1, c.Len())
+	require.Equal(t, 2, c.Stats().Size)
+	require.Equal(t, 2, c.Stats().Cost)
+	c.Peek("e")
+	require.Equal(t, 2, c.Stats().Size)
+	c.Get("e")
+	require.Equal(t, 1, c.Stats().Size)
+}
+
+func TestCacheOperations(t *testing.T) {
+	t.Run("peek and contains do not promote", func(t *testing.T) {
+		c := NewCacheOf[string, int](2)
+		c.Set("a", 1)
+		c.Set("b", 2)
+
+		val, ok := c.Peek("a")
+		require.True(t, ok)
+		require.Equal(t, 1, val)
+		require.True(t, c.Contains("a"))
+		require.False(t, c.Contains("x"))
+
+		c.Set("c", 3) // a не поднимался в очереди и вытесняется
+		require.False(t, c.Contains("a"))
+		require.Equal(t, Stats{Evictions: 1, Size: 2, Cost: 2}, c.Stats())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c := NewCacheOf[string, int](2)
+		var evicted []eviction
+		c.OnEvict(func(key string, value int, reason EvictionReason) {
+			evicted = append(evicted, eviction{key, value, reason})
+		})
+		c.Set("a", 1)
+		c.Set("b", 2)
+
+		require.True(t, c.Delete("a"))
+		require.False(t, c.Delete("a"))
+		require.Equal(t, []string{"b"}, c.Keys())
+		require.Equal(t, []eviction{{"a", 1, EvictedByDelete}}, evicted)
+
+		// освободившееся место занимается без вытеснения
+		c.Set("c", 3)
+		require.Equal(t, []string{"c", "b"}, c.Keys())
+		require.Equal(t, uint64(0), c.Stats().Evictions)
+	})
+
+	t.Run("keys in recency order", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheOf[string, int](3, withClock(clock.Now))
+		require.Empty(t, c.Keys())
+
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.SetWithTTL("c", 3, time.Second)
+		c.Get("a")
+		require.Equal(t, []string{"a", "c", "b"}, c.Keys())
+		require.Equal(t, 3, c.Len())
+
+		clock.Advance(time.Second)
+		require.Equal(t, []string{"a", "b"}, c.Keys())
+		// устаревший, но ещё не удалённый элемент не считается
+		require.Equal(t, 2, c.Len())
+		_, ok := c.Peek("c")
+		require.False(t, ok)
+	})
+
+	t.Run("resize", func(t *testing.T) {
+		c := NewCacheOf[int, int](5)
+		for i := 0; i < 5; i++ {
+			c.Set(i, i)
+		}
+		require.Equal(t, 3, c.Resize(2))
+		require.Equal(t, []int{4, 3}, c.Keys())
+
+		require.Equal(t, 0, c.Resize(3))
+		c.Set(5, 5)
+		c.Set(6, 6)
+		require.Equal(t, []int{6, 5, 4}, c.Keys())
+		require.Equal(t, uint64(4), c.Stats().Evictions)
+	})
+
+	t.Run("get or set", func(t *testing.T) {
+		c := NewCacheOf[string, int](2)
+		calls := 0
+		loader := func() (int, error) {
+			calls++
+			return 42, nil
+		}
+
+		val, err := c.GetOrSet("a", loader)
+		require.NoError(t, err)
+		require.Equal(t, 42, val)
+		val, err = c.GetOrSet("a", loader)
+		require.NoError(t, err)
+		require.Equal(t, 42, val)
+		require.Equal(t, 1, calls)
+
+		errLoad := errors.New("load failed")
+		_, err = c.GetOrSet("b", func() (int, error) { return 0, errLoad })
+		require.ErrorIs(t, err, errLoad)
+		require.False(t, c.Contains("b"))
+		require.Equal(t, Stats{Hits: 1, Misses: 2, Size: 1, Cost: 1}, c.Stats())
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		// ёмкости сегментов хватает на все ключи при любом распределении
+		c := NewShardedCache[int, int](32, 4)
+		for i := 0; i < 8; i++ {
+			c.Set(i, i)
+		}
+		require.True(t, c.Delete(0))
+		require.False(t, c.Contains(0))
+		require.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7}, c.Keys())
+		require.Equal(t, 7, c.Len())
+
+		c.Resize(4)
+		require.LessOrEqual(t, c.Len(), 4)
+		require.Len(t, c.Keys(), c.Len())
+	})
+}
+
+func TestWeightedCache(t *testing.T) {
+	byteLen := func(_ string, value []byte) int { return len(value) }
+
+	t.Run("evicts until cost fits", func(t *testing.T) {
+		c := NewWeightedCache[string, []byte](10, byteLen)
+		var evicted []string
+		c.OnEvict(func(key string, _ []byte, reason EvictionReason) {
+			require.Equal(t, EvictedByCapacity, reason)
+			evicted = append(evicted, key)
+		})
+
+		c.Set("a", make([]byte, 3))
+		c.Set("b", make([]byte, 3))
+		c.Set("c", make([]byte, 3))
+		c.Get("a")
+		c.Set("d", make([]byte, 6)) // вытесняет b и c
+		require.Equal(t, []string{"b", "c"}, evicted)
+		require.Equal(t, []string{"d", "a"}, c.Keys())
+		require.Equal(t, 9, c.Stats().Cost)
+	})
+
+	t.Run("update changes cost", func(t *testing.T) {
+		c := NewWeightedCache[string, []byte](10, byteLen)
+		c.Set("a", make([]byte, 4))
+		c.Set("b", make([]byte, 4))
+		require.True(t, c.Set("b", make([]byte, 8))) // вытесняет a
+		require.Equal(t, []string{"b"}, c.Keys())
+		require.Equal(t, Stats{Evictions: 1, Size: 1, Cost: 8}, c.Stats())
+
+		require.True(t, c.Set("b", make([]byte, 1)))
+		require.Equal(t, 1, c.Stats().Cost)
+	})
+
+	t.Run("entry larger than capacity is not stored", func(t *testing.T) {
+		c := NewWeightedCache[string, []byte](10, byteLen)
+		c.Set("a", make([]byte, 5))
+		c.Set("b", make([]byte, 5))
+
+		require.False(t, c.Set("big", make([]byte, 11)))
+		require.False(t, c.Contains("big"))
+		require.Equal(t, []string{"b", "a"}, c.Keys())
+
+		// прежнее значение не должно остаться в кэше
+		require.True(t, c.Set("a", make([]byte, 11)))
+		require.False(t, c.Contains("a"))
+		require.Equal(t, 5, c.Stats().Cost)
+	})
+
+	t.Run("resize and delete", func(t *testing.T) {
+		c := NewWeightedCache[string, []byte](10, byteLen)
+		c.Set("a", make([]byte, 2))
+		c.Set("b", make([]byte, 3))
+		c.Set("c", make([]byte, 4))
+
+		require.Equal(t, 1, c.Resize(7))
+		require.Equal(t, []string{"c", "b"}, c.Keys())
+		require.True(t, c.Delete("b"))
+		require.Equal(t, 4, c.Stats().Cost)
+
+		c.Clear()
+		require.Equal(t, Stats{Evictions: 1}, c.Stats())
+	})
+}
+
 // число итераций изменено 1kk -> 30k, чтобы тест не падал по таймауту.
 func TestCacheMultithreading(t *testing.T) {
 	c := NewCache(10)
@@ -124,3 +527,13 @@ func BenchmarkCache(b *testing.B) {
 		c.Get(Key(strconv.Itoa(rand.Intn(b.N))))
 	}
 }
+
+func BenchmarkCacheOf(b *testing.B) {
+	c := NewCacheOf[int, int](20)
+	for i := 0; i < b.N; i++ {
+		c.Set(i, i)
+	}
+	for i := 0; i < b.N; i++ {
+		c.Get(rand.Intn(b.N))
+	}
+}
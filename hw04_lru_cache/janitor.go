@@ -0,0 +1,41 @@
+package hw04lrucache
+
+import (
+	"context"
+	"time"
+)
+
+// Фоновая очистка: вызывает sweep раз в interval до вызова close или отмены ctx.
+type janitor struct {
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+func startJanitor(ctx context.Context, interval time.Duration, sweep func()) *janitor {
+	j := &janitor{done: make(chan struct{})}
+	ctx, j.stop = context.WithCancel(ctx)
+
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sweep()
+			}
+		}
+	}()
+	return j
+}
+
+// Остановить очистку и дождаться её завершения. Безопасно для nil.
+func (j *janitor) close() {
+	if j == nil {
+		return
+	}
+	j.stop()
+	<-j.done
+}
@@ -0,0 +1,73 @@
+package hw04lrucache
+
+// Политика LFU: элементы сгруппированы по числу обращений в корзины,
+// корзины упорядочены по возрастанию числа обращений. Внутри корзины элементы
+// упорядочены по давности использования, поэтому среди равных вытесняется давно использованный.
+// Все операции выполняются за O(1).
+type lfuPolicy[K comparable, V any] struct {
+	buckets ListOf[*lfuBucket[K, V]]
+}
+
+type lfuBucket[K comparable, V any] struct {
+	hits    int
+	entries entryList[K, V]
+}
+
+func newLFUPolicy[K comparable, V any]() *lfuPolicy[K, V] {
+	return &lfuPolicy[K, V]{buckets: NewListOf[*lfuBucket[K, V]]()}
+}
+
+func newLFUBucket[K comparable, V any](hits int) *lfuBucket[K, V] {
+	return &lfuBucket[K, V]{hits: hits, entries: newEntryList[K, V]()}
+}
+
+func (p *lfuPolicy[K, V]) admit(K, int, int) {}
+
+func (p *lfuPolicy[K, V]) add(e *cacheEntry[K, V]) {
+	first := p.buckets.Front()
+	if first == nil || first.Value.hits != 1 {
+		first = p.buckets.PushFront(newLFUBucket[K, V](1))
+	}
+	p.pushTo(first, e)
+}
+
+func (p *lfuPolicy[K, V]) hit(e *cacheEntry[K, V], cost int) {
+	current := e.bucket
+	current.Value.entries.setCost(e, cost)
+	next := current.Next
+	if next == nil || next.Value.hits != current.Value.hits+1 {
+		next = p.buckets.InsertAfter(newLFUBucket[K, V](current.Value.hits+1), current)
+	}
+	p.remove(e)
+	p.pushTo(next, e)
+}
+
+func (p *lfuPolicy[K, V]) pushTo(bucket *ListItemOf[*lfuBucket[K, V]], e *cacheEntry[K, V]) {
+	bucket.Value.entries.pushFront(e, segmentRecent)
+	e.bucket = bucket
+}
+
+func (p *lfuPolicy[K, V]) remove(e *cacheEntry[K, V]) {
+	bucket := e.bucket
+	bucket.Value.entries.remove(e)
+	e.bucket = nil
+	if bucket.Value.entries.entries.Len() == 0 {
+		p.buckets.Remove(bucket)
+	}
+}
+
+func (p *lfuPolicy[K, V]) evict(int) *cacheEntry[K, V] {
+	e := p.buckets.Front().Value.entries.back()
+	p.remove(e)
+	return e
+}
+
+func (p *lfuPolicy[K, V]) each(fn func(e *cacheEntry[K, V])) {
+	for bucket := p.buckets.Front(); bucket != nil; bucket = bucket.Next {
+		bucket.Value.entries.each(fn)
+	}
+}
+
+func (p *lfuPolicy[K, V]) clear() {
+	p.buckets = NewListOf[*lfuBucket[K, V]]()
+}
@@ -1,104 +1,175 @@
 package hw04lrucache
 
-type List interface {
+// Список значений произвольного типа, как и прежде возвращаемый NewList, и его элемент.
+type (
+	List     = ListOf[interface{}]
+	ListItem = ListItemOf[interface{}]
+)
+
+type ListOf[T any] interface {
 	Len() int
-	Front() *ListItem
-	Back() *ListItem
-	PushFront(v interface{}) *ListItem
-	PushBack(v interface{}) *ListItem
-	Remove(i *ListItem)
-	MoveToFront(i *ListItem)
-	pushFirstItem(i *ListItem)
+	Front() *ListItemOf[T]
+	Back() *ListItemOf[T]
+	PushFront(v T) *ListItemOf[T]
+	PushBack(v T) *ListItemOf[T]
+	// Вставить значение перед mark или после него. Если mark не принадлежит списку, вернуть nil.
+	InsertBefore(v T, mark *ListItemOf[T]) *ListItemOf[T]
+	InsertAfter(v T, mark *ListItemOf[T]) *ListItemOf[T]
+	// Добавить в конец копии значений другого списка, в том числе самого себя.
+	PushBackList(other ListOf[T])
+	// Чужой или уже удалённый элемент список не меняет.
+	Remove(i *ListItemOf[T])
+	MoveToFront(i *ListItemOf[T])
+	// Обойти элементы от первого к последнему или от последнего к первому, пока yield возвращает true.
+	// Текущий элемент можно удалить из yield.
+	Range(yield func(i *ListItemOf[T]) bool)
+	Backward(yield func(i *ListItemOf[T]) bool)
 }
-type ListItem struct {
-	Value interface{}
-	Next  *ListItem
-	Prev  *ListItem
+
+type ListItemOf[T any] struct {
+	Value T
+	Next  *ListItemOf[T]
+	Prev  *ListItemOf[T]
+	// список, которому принадлежит элемент; nil - элемент удалён
+	list *list[T]
 }
-type list struct {
-	First  *ListItem
-	Last   *ListItem
-	Length int
+
+// Корневой элемент root замыкает список: root.Next - первый элемент, root.Prev - последний.
+// Благодаря ему вставка и удаление не различают крайние элементы,
+// при этом у самих крайних элементов Prev и Next остаются nil.
+type list[T any] struct {
+	root   ListItemOf[T]
+	length int
 }
 
+// Список значений произвольного типа.
 func NewList() List {
-	return new(list)
+	return NewListOf[interface{}]()
+}
+
+// Типизированный список: значения хранятся в элементах без упаковки в interface{}.
+func NewListOf[T any]() ListOf[T] {
+	return new(list[T])
+}
+
+func (l *list[T]) Len() int {
+	return l.length
 }
 
-func (l *list) Len() int {
-	return l.Length
+func (l *list[T]) Front() *ListItemOf[T] {
+	return l.root.Next
 }
 
-func (l *list) Front() *ListItem {
-	return l.First
+func (l *list[T]) Back() *ListItemOf[T] {
+	return l.root.Prev
 }
 
-func (l *list) Back() *ListItem {
-	return l.Last
+func (l *list[T]) PushFront(v T) *ListItemOf[T] {
+	return l.insert(&ListItemOf[T]{Value: v}, &l.root)
 }
 
-func (l *list) PushFront(v interface{}) *ListItem {
-	newListItem := &ListItem{Value: v}
+func (l *list[T]) PushBack(v T) *ListItemOf[T] {
+	return l.insert(&ListItemOf[T]{Value: v}, l.prev(&l.root))
+}
 
-	if l.First == nil && l.Last == nil {
-		l.pushFirstItem(newListItem)
-		return newListItem
+func (l *list[T]) InsertBefore(v T, mark *ListItemOf[T]) *ListItemOf[T] {
+	if mark == nil || mark.list != l {
+		return nil
 	}
+	return l.insert(&ListItemOf[T]{Value: v}, l.prev(mark))
+}
 
-	if l.First != nil {
-		newListItem.Next = l.First
-		l.First.Prev = newListItem
-		l.First = newListItem
-		l.Length++
+func (l *list[T]) InsertAfter(v T, mark *ListItemOf[T]) *ListItemOf[T] {
+	if mark == nil || mark.list != l {
+		return nil
 	}
+	return l.insert(&ListItemOf[T]{Value: v}, mark)
+}
 
-	return newListItem
+func (l *list[T]) PushBackList(other ListOf[T]) {
+	// длина запоминается заранее, чтобы список можно было добавить к самому себе
+	for i, n := other.Front(), other.Len(); n > 0; i, n = i.Next, n-1 {
+		l.PushBack(i.Value)
+	}
 }
 
-func (l *list) PushBack(v interface{}) *ListItem {
-	newListItem := &ListItem{Value: v, Next: nil, Prev: nil}
+func (l *list[T]) Remove(i *ListItemOf[T]) {
+	if i == nil || i.list != l {
+		return
+	}
+	l.unlink(i)
+}
 
-	if l.First == nil && l.Last == nil {
-		l.pushFirstItem(newListItem)
-		return newListItem
+// Элемент перемещается без пересоздания, поэтому указатели на него остаются действительными.
+func (l *list[T]) MoveToFront(i *ListItemOf[T]) {
+	if i == nil || i.list != l || l.root.Next == i {
+		return
 	}
+	l.unlink(i)
+	l.insert(i, &l.root)
+}
 
-	if l.Last != nil {
-		newListItem.Prev = l.Last
-		l.Last.Next = newListItem
-		l.Last = newListItem
-		l.Length++
+func (l *list[T]) Range(yield func(i *ListItemOf[T]) bool) {
+	for i := l.Front(); i != nil; {
+		next := i.Next
+		if !yield(i) {
+			return
+		}
+		i = next
 	}
+}
+
+func (l *list[T]) Backward(yield func(i *ListItemOf[T]) bool) {
+	for i := l.Back(); i != nil; {
+		prev := i.Prev
+		if !yield(i) {
+			return
+		}
+		i = prev
+	}
+}
 
-	return newListItem
+// Вставить элемент после at, at может быть корнем.
+func (l *list[T]) insert(i, at *ListItemOf[T]) *ListItemOf[T] {
+	next := l.next(at)
+	l.link(at, i)
+	l.link(i, next)
+	i.list = l
+	l.length++
+	return i
 }
 
-func (l *list) Remove(i *ListItem) {
-	// Удалить последний элемент
+func (l *list[T]) unlink(i *ListItemOf[T]) {
+	l.link(l.prev(i), l.next(i))
+	i.Prev = nil
+	i.Next = nil
+	i.list = nil
+	l.length--
+}
+
+// Соседи элемента, у крайних элементов соседом считается корень.
+func (l *list[T]) next(i *ListItemOf[T]) *ListItemOf[T] {
 	if i.Next == nil {
-		i.Prev.Next = nil
-		l.Last = i.Prev
+		return &l.root
 	}
-	// Удалить первый элемент
-	if i.Prev == nil {
-		i.Next.Prev = nil
-		l.First = i.Next
-	}
-	// Удалить "средний" элемент
-	if i.Prev != nil && i.Next != nil {
-		i.Prev.Next = i.Next
-		i.Next.Prev = i.Prev
-	}
-	l.Length--
+	return i.Next
 }
 
-func (l *list) MoveToFront(i *ListItem) {
-	l.PushFront(i.Value)
-	l.Remove(i)
+func (l *list[T]) prev(i *ListItemOf[T]) *ListItemOf[T] {
+	if i.Prev == nil {
+		return &l.root
+	}
+	return i.Prev
 }
 
-func (l *list) pushFirstItem(i *ListItem) {
-	l.First = i
-	l.Last = i
-	l.Length++
+// Сделать prev и next соседями. Ссылки крайних элементов на корень заменяются на nil.
+func (l *list[T]) link(prev, next *ListItemOf[T]) {
+	prev.Next = next
+	next.Prev = prev
+	if prev == &l.root {
+		next.Prev = nil
+	}
+	if next == &l.root {
+		prev.Next = nil
+	}
 }
@@ -15,6 +15,13 @@ func TestList(t *testing.T) {
 		require.Nil(t, l.Back())
 	})
 
+	t.Run("non-generic types", func(t *testing.T) {
+		var l List = NewList()
+		var item *ListItem = l.PushBack("a")
+		require.Equal(t, "a", item.Value)
+		require.Same(t, item, l.Front())
+	})
+
 	t.Run("complex", func(t *testing.T) {
 		l := NewList()
 
@@ -50,4 +57,132 @@ func TestList(t *testing.T) {
 		}
 		require.Equal(t, []int{70, 80, 60, 40, 10, 30, 50}, elems)
 	})
+
+	t.Run("single element", func(t *testing.T) {
+		l := NewList()
+
+		item := l.PushBack(10)
+		l.MoveToFront(item)
+		require.Equal(t, item, l.Front())
+
+		l.Remove(item)
+		require.Equal(t, 0, l.Len())
+		require.Nil(t, l.Front())
+		require.Nil(t, l.Back())
+	})
+}
+
+func TestListOf(t *testing.T) {
+	l := NewListOf[string]()
+
+	a := l.PushBack("a")
+	b := l.PushBack("b")
+	c := l.PushBack("c") // [a, b, c]
+
+	// элементы перемещаются без пересоздания
+	l.MoveToFront(c) // [c, a, b]
+	require.Equal(t, c, l.Front())
+	require.Equal(t, b, l.Back())
+	require.Equal(t, a, c.Next)
+	require.Nil(t, c.Prev)
+
+	l.Remove(b) // [c, a]
+	require.Equal(t, 2, l.Len())
+	require.Equal(t, a, l.Back())
+	require.Nil(t, a.Next)
+
+	elems := make([]string, 0, l.Len())
+	for i := l.Back(); i != nil; i = i.Prev {
+		elems = append(elems, i.Value)
+	}
+	require.Equal(t, []string{"a", "c"}, elems)
+}
+
+func listValues[T any](l ListOf[T]) []T {
+	values := make([]T, 0, l.Len())
+	l.Range(func(i *ListItemOf[T]) bool {
+		values = append(values, i.Value)
+		return true
+	})
+	return values
+}
+
+func TestListInsert(t *testing.T) {
+	l := NewListOf[int]()
+	mid := l.PushBack(2)
+	require.Equal(t, 1, l.InsertBefore(1, mid).Value)
+	require.Equal(t, 3, l.InsertAfter(3, mid).Value)
+	l.InsertAfter(4, l.Back())
+	l.InsertBefore(0, l.Front())
+	require.Equal(t, []int{0, 1, 2, 3, 4}, listValues(l))
+	require.Nil(t, l.Front().Prev)
+	require.Nil(t, l.Back().Next)
+
+	l.PushBackList(l)
+	require.Equal(t, []int{0, 1, 2, 3, 4, 0, 1, 2, 3, 4}, listValues(l))
+
+	other := NewListOf[int]()
+	other.PushBackList(l)
+	other.Front().Value = 100 // значения копируются
+	require.Equal(t, 10, other.Len())
+	require.Equal(t, 0, l.Front().Value)
+}
+
+func TestListForeignItems(t *testing.T) {
+	l := NewListOf[string]()
+	a := l.PushBack("a")
+	l.PushBack("b")
+	other := NewListOf[string]()
+	x := other.PushBack("x")
+
+	l.Remove(x)
+	l.MoveToFront(x)
+	require.Nil(t, l.InsertBefore("y", x))
+	require.Nil(t, l.InsertAfter("y", x))
+	require.Nil(t, l.InsertAfter("y", nil))
+	l.Remove(nil)
+	require.Equal(t, []string{"a", "b"}, listValues(l))
+	require.Equal(t, []string{"x"}, listValues(other))
+
+	// повторное удаление не портит длину
+	l.Remove(a)
+	l.Remove(a)
+	l.MoveToFront(a)
+	require.Equal(t, 1, l.Len())
+	require.Equal(t, []string{"b"}, listValues(l))
+
+	l.Remove(l.Front())
+	require.Equal(t, 0, l.Len())
+	require.Nil(t, l.Front())
+	require.Nil(t, l.Back())
+}
+
+func TestListIterators(t *testing.T) {
+	l := NewListOf[int]()
+	for i := 1; i <= 6; i++ {
+		l.PushBack(i)
+	}
+
+	var backward []int
+	l.Backward(func(i *ListItemOf[int]) bool {
+		backward = append(backward, i.Value)
+		return i.Value > 3
+	})
+	require.Equal(t, []int{6, 5, 4, 3}, backward)
+
+	// удаление текущего элемента не прерывает обход
+	l.Range(func(i *ListItemOf[int]) bool {
+		if i.Value%2 == 0 {
+			l.Remove(i)
+		}
+		return true
+	})
+	require.Equal(t, []int{1, 3, 5}, listValues(l))
+
+	var empty []int
+	NewListOf[int]().Range(func(i *ListItemOf[int]) bool {
+		empty = append(empty, i.Value)
+		return true
+	})
+	require.Empty(t, empty)
 }
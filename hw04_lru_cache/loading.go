@@ -0,0 +1,162 @@
+package hw04lrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Ошибка, которую получают вызывающие, если загрузчик запаниковал.
+var ErrLoaderPanic = errors.New("loader panicked")
+
+// Загрузчик значения по ключу, например запрос в медленное хранилище.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// Кэш, который сам загружает отсутствующие значения.
+// Одновременные промахи по одному ключу приводят к одному вызову загрузчика,
+// остальные вызывающие ждут его результата.
+type LoadingCache[K comparable, V any] struct {
+	cache  CacheOf[K, loadedValue[V]]
+	loader Loader[K, V]
+
+	ttl          time.Duration
+	errorTTL     time.Duration
+	refreshAhead time.Duration
+	now          func() time.Time
+
+	mu    sync.Mutex
+	calls map[K]*loadCall[V]
+}
+
+// Значение или ошибка загрузчика, хранимые в кэше.
+type loadedValue[V any] struct {
+	value V
+	err   error
+	// нулевое время - значение не устаревает
+	expiresAt time.Time
+}
+
+// Выполняющийся вызов загрузчика, done закрывается по его завершении.
+type loadCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// ключ инвалидирован во время загрузки, результат не сохраняется в кэш; под LoadingCache.mu
+	invalidated bool
+}
+
+// Кэш ёмкостью capacity, заполняемый через loader.
+// Поддерживает те же опции, что и NewCacheOf, а также WithErrorTTL и WithRefreshAhead.
+func NewLoadingCache[K comparable, V any](capacity int, loader Loader[K, V], opts ...Option) *LoadingCache[K, V] {
+	o := newOptions(opts)
+	return &LoadingCache[K, V]{
+		cache:        NewCacheOf[K, loadedValue[V]](capacity, opts...),
+		loader:       loader,
+		ttl:          o.defaultTTL,
+		errorTTL:     o.errorTTL,
+		refreshAhead: o.refreshAhead,
+		now:          o.now,
+		calls:        make(map[K]*loadCall[V]),
+	}
+}
+
+// Вернуть значение из кэша или дождаться его загрузки.
+// Отмена ctx прерывает только ожидание: загрузка продолжается и её результат попадёт в кэш.
+func (lc *LoadingCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	if loaded, ok := lc.cache.Get(key); ok {
+		if loaded.err == nil && lc.needsRefresh(loaded) {
+			lc.load(ctx, key, true)
+		}
+		return loaded.value, loaded.err
+	}
+
+	call := lc.load(ctx, key, false)
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (lc *LoadingCache[K, V]) needsRefresh(loaded loadedValue[V]) bool {
+	return lc.refreshAhead > 0 && !loaded.expiresAt.IsZero() &&
+		!lc.now().Before(loaded.expiresAt.Add(-lc.refreshAhead))
+}
+
+// Запустить загрузчик, если для ключа он ещё не выполняется.
+// При фоновом обновлении (refresh) ошибка не вытесняет прежнее значение из кэша.
+func (lc *LoadingCache[K, V]) load(ctx context.Context, key K, refresh bool) *loadCall[V] {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if call, ok := lc.calls[key]; ok {
+		return call
+	}
+
+	call := &loadCall[V]{done: make(chan struct{})}
+	lc.calls[key] = call
+	// загрузка не должна прерываться, если её инициатор перестал ждать
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		call.value, call.err = lc.callLoader(ctx, key)
+
+		// результат сохраняется под той же блокировкой, что и Invalidate,
+		// и уже в кэше, когда новые вызовы Get перестают находить загрузку
+		lc.mu.Lock()
+		if !call.invalidated {
+			lc.store(key, call.value, call.err, refresh)
+			delete(lc.calls, key)
+		}
+		lc.mu.Unlock()
+		close(call.done)
+	}()
+	return call
+}
+
+// Вызвать загрузчик, превратив его панику в ошибку: загрузчик выполняется
+// в отдельной горутине, и иначе паника завершила бы весь процесс.
+func (lc *LoadingCache[K, V]) callLoader(ctx context.Context, key K) (value V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+		}
+	}()
+	return lc.loader(ctx, key)
+}
+
+func (lc *LoadingCache[K, V]) store(key K, value V, err error, refresh bool) {
+	switch {
+	case err == nil:
+		loaded := loadedValue[V]{value: value}
+		if lc.ttl > 0 {
+			loaded.expiresAt = lc.now().Add(lc.ttl)
+		}
+		lc.cache.SetWithTTL(key, loaded, lc.ttl)
+	case lc.errorTTL > 0 && !refresh:
+		lc.cache.SetWithTTL(key, loadedValue[V]{err: err}, lc.errorTTL)
+	}
+}
+
+// Удалить значение или запомненную ошибку, чтобы следующий Get загрузил ключ заново.
+// Результат загрузки, начатой до вызова Invalidate, получат её ожидающие, но в кэш он не попадёт.
+func (lc *LoadingCache[K, V]) Invalidate(key K) bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if call, ok := lc.calls[key]; ok {
+		call.invalidated = true
+		delete(lc.calls, key)
+	}
+	return lc.cache.Delete(key)
+}
+
+func (lc *LoadingCache[K, V]) Stats() Stats {
+	return lc.cache.Stats()
+}
+
+// Остановить фоновую очистку. Уже запущенные загрузки завершаются сами.
+func (lc *LoadingCache[K, V]) Close() {
+	lc.cache.Close()
+}
@@ -0,0 +1,200 @@
+package hw04lrucache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadingCache(t *testing.T) {
+	t.Run("concurrent misses call loader once", func(t *testing.T) {
+		var calls int32
+		release := make(chan struct{})
+		c := NewLoadingCache[string, int](10, func(_ context.Context, key string) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return len(key), nil
+		})
+
+		const callers = 20
+		results := make(chan int, callers)
+		wg := sync.WaitGroup{}
+		wg.Add(callers)
+		for i := 0; i < callers; i++ {
+			go func() {
+				defer wg.Done()
+				val, err := c.Get(context.Background(), "abc")
+				if err != nil {
+					val = -1
+				}
+				results <- val
+			}()
+		}
+		// ждём, пока загрузка начнётся, и отпускаем её
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 1 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+		close(results)
+
+		for val := range results {
+			require.Equal(t, 3, val)
+		}
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+		val, err := c.Get(context.Background(), "abc")
+		require.NoError(t, err)
+		require.Equal(t, 3, val)
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("negative caching", func(t *testing.T) {
+		clock := newFakeClock()
+		errBackend := errors.New("backend unavailable")
+		calls := 0
+		loader := func(context.Context, string) (int, error) {
+			calls++
+			return 0, errBackend
+		}
+
+		c := NewLoadingCache[string, int](10, loader, WithErrorTTL(time.Second), withClock(clock.Now))
+		_, err := c.Get(context.Background(), "a")
+		require.ErrorIs(t, err, errBackend)
+		_, err = c.Get(context.Background(), "a")
+		require.ErrorIs(t, err, errBackend)
+		require.Equal(t, 1, calls)
+
+		clock.Advance(time.Second)
+		_, err = c.Get(context.Background(), "a")
+		require.ErrorIs(t, err, errBackend)
+		require.Equal(t, 2, calls)
+
+		require.True(t, c.Invalidate("a"))
+		_, err = c.Get(context.Background(), "a")
+		require.ErrorIs(t, err, errBackend)
+		require.Equal(t, 3, calls)
+
+		// без WithErrorTTL ошибки не запоминаются
+		calls = 0
+		c = NewLoadingCache[string, int](10, loader)
+		c.Get(context.Background(), "a")
+		c.Get(context.Background(), "a")
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("refresh ahead", func(t *testing.T) {
+		clock := newFakeClock()
+		var version int32
+		var failRefresh atomic.Bool
+		c := NewLoadingCache[string, int32](10, func(context.Context, string) (int32, error) {
+			if failRefresh.Load() {
+				return 0, errors.New("refresh failed")
+			}
+			return atomic.AddInt32(&version, 1), nil
+		}, WithDefaultTTL(time.Minute), WithRefreshAhead(10*time.Second), withClock(clock.Now))
+		get := func() int32 {
+			val, err := c.Get(context.Background(), "a")
+			require.NoError(t, err)
+			return val
+		}
+
+		require.Equal(t, int32(1), get())
+		clock.Advance(49 * time.Second)
+		require.Equal(t, int32(1), get())
+		require.Equal(t, int32(1), atomic.LoadInt32(&version))
+
+		// значение отдаётся сразу, а обновление идёт в фоне
+		clock.Advance(time.Second)
+		require.Equal(t, int32(1), get())
+		require.Eventually(t, func() bool {
+			val, _ := c.cache.Peek("a")
+			return val.value == 2
+		}, time.Second, time.Millisecond)
+		require.Equal(t, int32(2), get())
+
+		// ошибка фонового обновления не вытесняет прежнее значение
+		failRefresh.Store(true)
+		clock.Advance(50 * time.Second)
+		require.Equal(t, int32(2), get())
+		require.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return len(c.calls) == 0
+		}, time.Second, time.Millisecond)
+		require.Equal(t, int32(2), get())
+	})
+
+	t.Run("invalidate during load", func(t *testing.T) {
+		var calls int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+		c := NewLoadingCache[string, int32](10, func(context.Context, string) (int32, error) {
+			call := atomic.AddInt32(&calls, 1)
+			if call == 1 {
+				close(started)
+				<-release
+			}
+			return call, nil
+		})
+
+		result := make(chan int32)
+		go func() {
+			val, _ := c.Get(context.Background(), "a")
+			result <- val
+		}()
+		<-started
+		require.False(t, c.Invalidate("a"))
+		close(release)
+		// ожидающий получает загруженное значение, но в кэш оно не попадает
+		require.Equal(t, int32(1), <-result)
+
+		val, err := c.Get(context.Background(), "a")
+		require.NoError(t, err)
+		require.Equal(t, int32(2), val)
+		val, err = c.Get(context.Background(), "a")
+		require.NoError(t, err)
+		require.Equal(t, int32(2), val)
+	})
+
+	t.Run("loader panic is returned as error", func(t *testing.T) {
+		calls := 0
+		c := NewLoadingCache[string, int](10, func(context.Context, string) (int, error) {
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+			return 1, nil
+		})
+
+		_, err := c.Get(context.Background(), "a")
+		require.ErrorIs(t, err, ErrLoaderPanic)
+		require.Contains(t, err.Error(), "boom")
+
+		val, err := c.Get(context.Background(), "a")
+		require.NoError(t, err)
+		require.Equal(t, 1, val)
+	})
+
+	t.Run("caller stops waiting on context cancel", func(t *testing.T) {
+		release := make(chan struct{})
+		c := NewLoadingCache[string, int](10, func(ctx context.Context, _ string) (int, error) {
+			<-release
+			// загрузка не отменяется вместе с контекстом вызывающего
+			return 1, ctx.Err()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := c.Get(ctx, "a")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(release)
+		val, err := c.Get(context.Background(), "a")
+		require.NoError(t, err)
+		require.Equal(t, 1, val)
+	})
+}
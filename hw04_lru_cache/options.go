@@ -0,0 +1,82 @@
+package hw04lrucache
+
+import (
+	"context"
+	"time"
+)
+
+// Option настраивает кэш, создаваемый NewCacheOf и другими конструкторами.
+type Option func(*options)
+
+type options struct {
+	defaultTTL      time.Duration
+	janitorCtx      context.Context
+	janitorInterval time.Duration
+	now             func() time.Time
+	policy          Policy
+	codec           Codec
+
+	// только для LoadingCache
+	errorTTL     time.Duration
+	refreshAhead time.Duration
+}
+
+func newOptions(opts []Option) options {
+	o := options{now: time.Now, codec: GobCodec}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Время жизни элементов, добавленных через Set. По умолчанию элементы не устаревают.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.defaultTTL = ttl
+	}
+}
+
+// Фоновая очистка устаревших элементов раз в interval.
+// Останавливается вызовом Close или отменой ctx.
+func WithJanitor(ctx context.Context, interval time.Duration) Option {
+	return func(o *options) {
+		o.janitorCtx = ctx
+		o.janitorInterval = interval
+	}
+}
+
+// Политика вытеснения, по умолчанию PolicyLRU.
+func WithPolicy(p Policy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// Кодек снимков SaveTo и LoadFrom, по умолчанию GobCodec.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+func withClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// Время, на которое LoadingCache запоминает ошибку загрузчика.
+// По умолчанию ошибки не кэшируются и следующий Get снова вызывает загрузчик.
+func WithErrorTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.errorTTL = ttl
+	}
+}
+
+// LoadingCache перезагружает значение в фоне, если до его устаревания осталось меньше window.
+// Пока идёт перезагрузка, Get возвращает прежнее значение. Работает вместе с WithDefaultTTL.
+func WithRefreshAhead(window time.Duration) Option {
+	return func(o *options) {
+		o.refreshAhead = window
+	}
+}
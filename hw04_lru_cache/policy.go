@@ -0,0 +1,194 @@
+package hw04lrucache
+
+// Политика вытеснения, выбирается опцией WithPolicy.
+type Policy int
+
+const (
+	// Вытесняется давно использованный элемент.
+	PolicyLRU Policy = iota
+	// Вытесняется элемент с наименьшим числом обращений, среди равных - давно использованный.
+	PolicyLFU
+	// 2Q: новые элементы попадают в короткую очередь FIFO и переходят в основную LRU-очередь,
+	// только если к ним обратились снова после вытеснения. Однократные обращения и
+	// последовательные проходы по ключам не вытесняют часто используемые элементы.
+	Policy2Q
+	// ARC: ёмкость адаптивно делится между недавно и часто используемыми элементами
+	// по истории вытесненных ключей.
+	PolicyARC
+)
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyLRU:
+		return "lru"
+	case PolicyLFU:
+		return "lfu"
+	case Policy2Q:
+		return "2q"
+	case PolicyARC:
+		return "arc"
+	}
+	return "unknown"
+}
+
+// Политика хранит порядок элементов кэша, а словарь ключей, стоимость,
+// время жизни и уведомления остаются за кэшем.
+type evictionPolicy[K comparable, V any] interface {
+	// Вызывается перед добавлением нового ключа, до освобождения места под него.
+	admit(key K, cost, capacity int)
+	add(e *cacheEntry[K, V])
+	// Обращение к элементу, при записи с новой стоимостью cost.
+	hit(e *cacheEntry[K, V], cost int)
+	remove(e *cacheEntry[K, V])
+	// Выбрать элемент для вытеснения и исключить его из политики. Вызывается только для непустого кэша.
+	evict(capacity int) *cacheEntry[K, V]
+	// Обойти элементы от кандидата на вытеснение к наиболее ценному. fn не должна менять кэш.
+	each(fn func(e *cacheEntry[K, V]))
+	clear()
+}
+
+func newPolicy[K comparable, V any](p Policy) evictionPolicy[K, V] {
+	switch p {
+	case PolicyLFU:
+		return newLFUPolicy[K, V]()
+	case Policy2Q:
+		return newTwoQueuePolicy[K, V]()
+	case PolicyARC:
+		return newARCPolicy[K, V]()
+	case PolicyLRU:
+	}
+	return newLRUPolicy[K, V]()
+}
+
+type lruPolicy[K comparable, V any] struct {
+	queue entryList[K, V]
+}
+
+func newLRUPolicy[K comparable, V any]() *lruPolicy[K, V] {
+	return &lruPolicy[K, V]{queue: newEntryList[K, V]()}
+}
+
+func (p *lruPolicy[K, V]) admit(K, int, int) {}
+
+func (p *lruPolicy[K, V]) add(e *cacheEntry[K, V]) {
+	p.queue.pushFront(e, segmentRecent)
+}
+
+func (p *lruPolicy[K, V]) hit(e *cacheEntry[K, V], cost int) {
+	p.queue.setCost(e, cost)
+	p.queue.entries.MoveToFront(e.elem)
+}
+
+func (p *lruPolicy[K, V]) remove(e *cacheEntry[K, V]) {
+	p.queue.remove(e)
+}
+
+func (p *lruPolicy[K, V]) evict(int) *cacheEntry[K, V] {
+	e := p.queue.back()
+	p.queue.remove(e)
+	return e
+}
+
+func (p *lruPolicy[K, V]) each(fn func(e *cacheEntry[K, V])) {
+	p.queue.each(fn)
+}
+
+func (p *lruPolicy[K, V]) clear() {
+	p.queue = newEntryList[K, V]()
+}
+
+// Очередь, в которой находится элемент, для политик с несколькими очередями.
+type segment uint8
+
+const (
+	segmentRecent   segment = iota // элементы, к которым обращались один раз
+	segmentFrequent                // элементы, к которым обращались повторно
+)
+
+// Список элементов кэша с их суммарной стоимостью.
+type entryList[K comparable, V any] struct {
+	entries ListOf[*cacheEntry[K, V]]
+	cost    int
+}
+
+func newEntryList[K comparable, V any]() entryList[K, V] {
+	return entryList[K, V]{entries: NewListOf[*cacheEntry[K, V]]()}
+}
+
+func (l *entryList[K, V]) pushFront(e *cacheEntry[K, V], s segment) {
+	e.elem = l.entries.PushFront(e)
+	e.segment = s
+	l.cost += e.cost
+}
+
+func (l *entryList[K, V]) remove(e *cacheEntry[K, V]) {
+	l.entries.Remove(e.elem)
+	e.elem = nil
+	l.cost -= e.cost
+}
+
+func (l *entryList[K, V]) setCost(e *cacheEntry[K, V], cost int) {
+	l.cost += cost - e.cost
+	e.cost = cost
+}
+
+func (l *entryList[K, V]) back() *cacheEntry[K, V] {
+	if item := l.entries.Back(); item != nil {
+		return item.Value
+	}
+	return nil
+}
+
+func (l *entryList[K, V]) each(fn func(e *cacheEntry[K, V])) {
+	for item := l.entries.Back(); item != nil; item = item.Prev {
+		fn(item.Value)
+	}
+}
+
+// История вытесненных ключей без значений: по ней 2Q и ARC узнают ключи,
+// которые запросили снова вскоре после вытеснения.
+type ghostList[K comparable] struct {
+	keys  ListOf[ghost[K]]
+	items map[K]*ListItemOf[ghost[K]]
+	cost  int
+}
+
+type ghost[K comparable] struct {
+	key  K
+	cost int
+}
+
+func newGhostList[K comparable]() ghostList[K] {
+	return ghostList[K]{keys: NewListOf[ghost[K]](), items: make(map[K]*ListItemOf[ghost[K]])}
+}
+
+func (g *ghostList[K]) len() int {
+	return g.keys.Len()
+}
+
+func (g *ghostList[K]) push(key K, cost int) {
+	g.items[key] = g.keys.PushFront(ghost[K]{key: key, cost: cost})
+	g.cost += cost
+}
+
+// Удалить ключ из истории. return = стоимость вытесненного элемента и флаг, был ли ключ в истории.
+func (g *ghostList[K]) remove(key K) (int, bool) {
+	item, ok := g.items[key]
+	if !ok {
+		return 0, false
+	}
+	g.removeItem(item)
+	return item.Value.cost, true
+}
+
+func (g *ghostList[K]) removeOldest() {
+	if item := g.keys.Back(); item != nil {
+		g.removeItem(item)
+	}
+}
+
+func (g *ghostList[K]) removeItem(item *ListItemOf[ghost[K]]) {
+	g.keys.Remove(item)
+	delete(g.items, item.Value.key)
+	g.cost -= item.Value.cost
+}
@@ -0,0 +1,207 @@
+package hw04lrucache
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var policies = []Policy{PolicyLRU, PolicyLFU, Policy2Q, PolicyARC}
+
+// Общие для всех политик проверки: политика меняет только выбор вытесняемого элемента.
+func TestPolicies(t *testing.T) {
+	for _, policy := range policies {
+		policy := policy
+		t.Run(policy.String(), func(t *testing.T) {
+			testPolicy(t, policy)
+		})
+	}
+}
+
+func testPolicy(t *testing.T, policy Policy) {
+	t.Helper()
+
+	t.Run("set and get", func(t *testing.T) {
+		c := NewCacheOf[string, int](5, WithPolicy(policy))
+		require.False(t, c.Set("a", 1))
+		require.True(t, c.Set("a", 2))
+
+		val, ok := c.Get("a")
+		require.True(t, ok)
+		require.Equal(t, 2, val)
+		_, ok = c.Get("b")
+		require.False(t, ok)
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		c := NewCacheOf[int, int](10, WithPolicy(policy))
+		var evicted int
+		c.OnEvict(func(_ int, _ int, reason EvictionReason) {
+			require.Equal(t, EvictedByCapacity, reason)
+			evicted++
+		})
+		for i := 0; i < 100; i++ {
+			c.Set(i, i)
+			c.Get(i % 7)
+		}
+		require.Equal(t, 10, c.Len())
+		require.Len(t, c.Keys(), 10)
+		require.Equal(t, 90, evicted)
+		stats := c.Stats()
+		require.Equal(t, uint64(90), stats.Evictions)
+		require.Equal(t, 10, stats.Size)
+		require.Equal(t, 10, stats.Cost)
+		// только что добавленный элемент не вытесняется
+		require.True(t, c.Contains(99))
+	})
+
+	t.Run("delete, resize and clear", func(t *testing.T) {
+		c := NewCacheOf[int, int](10, WithPolicy(policy))
+		for i := 0; i < 10; i++ {
+			c.Set(i, i)
+		}
+		require.True(t, c.Delete(3))
+		require.False(t, c.Contains(3))
+		require.Equal(t, 9, c.Len())
+
+		require.Equal(t, 5, c.Resize(4))
+		require.Len(t, c.Keys(), 4)
+		c.Clear()
+		require.Empty(t, c.Keys())
+		require.Equal(t, Stats{Evictions: 5}, c.Stats())
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheOf[string, int](5, WithPolicy(policy), WithDefaultTTL(time.Second), withClock(clock.Now))
+		c.Set("a", 1)
+		c.SetWithTTL("b", 2, 0)
+		clock.Advance(time.Second)
+		require.False(t, c.Contains("a"))
+		require.Equal(t, []string{"b"}, c.Keys())
+		_, ok := c.Get("a")
+		require.False(t, ok)
+		require.Equal(t, 1, c.Len())
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		c := NewWeightedCache[int, int](20, func(_ int, v int) int { return v }, WithPolicy(policy))
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 1000; i++ {
+			key := r.Intn(50)
+			c.Set(key, r.Intn(8))
+			require.LessOrEqual(t, c.Stats().Cost, 20)
+		}
+	})
+
+	t.Run("random operations keep cache consistent", func(t *testing.T) {
+		c := NewCacheOf[int, int](16, WithPolicy(policy))
+		lc := c.(*lruCache[int, int])
+		r := rand.New(rand.NewSource(42))
+		for i := 0; i < 10_000; i++ {
+			key := r.Intn(64)
+			switch op := r.Intn(100); {
+			case op < 50:
+				c.Get(key)
+			case op < 80:
+				c.Set(key, i)
+			case op < 90:
+				c.SetWithTTL(key, i, time.Duration(r.Intn(2))*time.Hour)
+			case op < 99:
+				c.Delete(key)
+			default:
+				c.Resize(8 + r.Intn(16))
+			}
+			requireConsistent(t, lc)
+		}
+	})
+}
+
+// Словарь кэша и очереди политики содержат одни и те же элементы, ёмкость не превышена.
+func requireConsistent[K comparable, V any](t *testing.T, lc *lruCache[K, V]) {
+	t.Helper()
+	count, cost, expiring := 0, 0, 0
+	lc.policy.each(func(e *cacheEntry[K, V]) {
+		count++
+		cost += e.cost
+		if !e.expiresAt.IsZero() {
+			expiring++
+		}
+		require.Same(t, lc.items[e.key], e)
+	})
+	require.Equal(t, len(lc.items), count)
+	require.Equal(t, lc.totalCost, cost)
+	require.Equal(t, lc.expiring, expiring)
+	require.Len(t, lc.Keys(), lc.Len())
+	require.LessOrEqual(t, lc.totalCost, lc.capacity)
+}
+
+func TestPolicyLFU(t *testing.T) {
+	c := NewCacheOf[string, int](2, WithPolicy(PolicyLFU))
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("a")
+	c.Set("b", 2)
+	c.Set("c", 3) // b и c использованы по разу, вытесняется более давний b
+	require.Equal(t, []string{"a", "c"}, c.Keys())
+
+	c.Get("c")
+	c.Get("c")
+	c.Get("c")
+	c.Set("d", 4) // теперь у c больше обращений, чем у a
+	require.Equal(t, []string{"c", "d"}, c.Keys())
+}
+
+// Однократный проход по множеству ключей не вытесняет используемый повторно элемент,
+// тогда как LRU его теряет.
+func TestPolicyScanResistance(t *testing.T) {
+	scan := func(policy Policy) bool {
+		c := NewCacheOf[string, int](8, WithPolicy(policy))
+		// 2Q переносит элемент в основную очередь, только если его запросили после вытеснения
+		c.Set("hot", 0)
+		for i := 0; i < 8; i++ {
+			c.Set("warmup"+strconv.Itoa(i), i)
+		}
+		if !c.Contains("hot") {
+			c.Set("hot", 0)
+		}
+		c.Get("hot")
+
+		for i := 0; i < 100; i++ {
+			c.Set("scan"+strconv.Itoa(i), i)
+		}
+		return c.Contains("hot")
+	}
+
+	require.False(t, scan(PolicyLRU))
+	require.True(t, scan(PolicyLFU))
+	require.True(t, scan(Policy2Q))
+	require.True(t, scan(PolicyARC))
+}
+
+func TestPolicyARCAdapts(t *testing.T) {
+	c := NewCacheOf[int, int](4, WithPolicy(PolicyARC))
+	arc := c.(*lruCache[int, int]).policy.(*arcPolicy[int, int])
+	for i := 0; i < 6; i++ {
+		c.Set(i, i)
+	}
+	// история recent вместе с очередью не превышает ёмкость: в ней остался последний вытесненный ключ
+	require.Equal(t, 0, arc.target)
+	require.Equal(t, 1, arc.recentGhosts.len())
+
+	// ключ из истории recent: доля recent растёт, а ключ попадает в frequent
+	c.Set(1, 1)
+	require.Equal(t, 1, arc.target)
+	require.Equal(t, segmentFrequent, c.(*lruCache[int, int]).items[1].segment)
+	// место под него освобождено вытеснением следующего по давности ключа в историю
+	require.Contains(t, arc.recentGhosts.items, 2)
+	require.NotContains(t, arc.recentGhosts.items, 1)
+}
+
+func TestPolicyString(t *testing.T) {
+	require.Equal(t, "2q", Policy2Q.String())
+	require.Equal(t, "unknown", Policy(-1).String())
+}
@@ -0,0 +1,123 @@
+package hw04lrucache
+
+import (
+	"bufio"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Записанные трассы обращений к кэшу кладутся в testdata/traces/*.trace: по одному ключу на строку,
+// пустые строки и строки с # пропускаются, в заголовке описано, как трасса записана.
+// Они воспроизводятся вместе с синтетическими трассами:
+//
+//	go test -run xxx -bench Replay
+//
+// Метрика hit% - доля попаданий при воспроизведении трассы.
+const replayCapacity = 1000
+
+type trace struct {
+	name string
+	keys []string
+}
+
+func readTrace(r io.Reader) ([]string, error) {
+	var keys []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
+
+func recordedTraces(tb testing.TB) []trace {
+	tb.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "traces", "*.trace"))
+	require.NoError(tb, err)
+
+	traces := make([]trace, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		require.NoError(tb, err)
+		keys, err := readTrace(f)
+		f.Close()
+		require.NoError(tb, err)
+		traces = append(traces, trace{name: strings.TrimSuffix(filepath.Base(path), ".trace"), keys: keys})
+	}
+	return traces
+}
+
+// Синтетические трассы: распределение Ципфа, оно же с периодическими проходами
+// по новым ключам и циклический проход по набору чуть больше ёмкости.
+func syntheticTraces() []trace {
+	const size = 100_000
+	r := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(r, 1.1, 1, 20*replayCapacity)
+
+	var zipfKeys, scanKeys, loopKeys []string
+	scanned := 0
+	for i := 0; i < size; i++ {
+		key := "z" + strconv.FormatUint(zipf.Uint64(), 10)
+		zipfKeys = append(zipfKeys, key)
+		// каждые 10 тысяч обращений - проход по 2 ёмкостям новых ключей
+		if i%10_000 < 2*replayCapacity {
+			scanned++
+			key = "s" + strconv.Itoa(scanned)
+		}
+		scanKeys = append(scanKeys, key)
+		loopKeys = append(loopKeys, "l"+strconv.Itoa(i%(replayCapacity+replayCapacity/10)))
+	}
+	return []trace{
+		{name: "zipf", keys: zipfKeys},
+		{name: "zipf+scan", keys: scanKeys},
+		{name: "loop", keys: loopKeys},
+	}
+}
+
+func replay(c CacheOf[string, struct{}], keys []string) (hits int) {
+	for _, key := range keys {
+		if _, ok := c.Get(key); ok {
+			hits++
+			continue
+		}
+		c.Set(key, struct{}{})
+	}
+	return hits
+}
+
+func BenchmarkReplay(b *testing.B) {
+	for _, tr := range append(syntheticTraces(), recordedTraces(b)...) {
+		for _, policy := range policies {
+			tr, policy := tr, policy
+			b.Run(tr.name+"/"+policy.String(), func(b *testing.B) {
+				var hits int
+				for i := 0; i < b.N; i++ {
+					hits = replay(NewCacheOf[string, struct{}](replayCapacity, WithPolicy(policy)), tr.keys)
+				}
+				b.ReportMetric(100*float64(hits)/float64(len(tr.keys)), "hit%")
+			})
+		}
+	}
+}
+
+func TestReadTrace(t *testing.T) {
+	keys, err := readTrace(strings.NewReader("# recorded 2024-01-01\na\n\n  b \na\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "a"}, keys)
+
+	traces := recordedTraces(t)
+	require.NotEmpty(t, traces)
+	for _, tr := range traces {
+		require.NotEmpty(t, tr.keys, tr.name)
+	}
+}
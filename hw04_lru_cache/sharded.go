@@ -0,0 +1,289 @@
+package hw04lrucache
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Кэш из нескольких независимых LRU-сегментов, ключи распределяются по хэшу.
+// Каждый сегмент защищён своей блокировкой, поэтому параллельные обращения
+// к разным сегментам не мешают друг другу. Порядок вытеснения - LRU внутри сегмента.
+type shardedCache[K comparable, V any] struct {
+	codec   Codec
+	seed    maphash.Seed
+	shards  []*lruCache[K, V]
+	janitor *janitor
+}
+
+// Сегментированный кэш общей ёмкостью capacity, разделённой поровну между shards сегментами.
+// Сегментов не больше, чем ёмкость: в каждом помещается хотя бы один элемент.
+func NewShardedCache[K comparable, V any](capacity, shards int, opts ...Option) CacheOf[K, V] {
+	shards = max(min(shards, capacity), 1)
+	o := newOptions(opts)
+	sc := &shardedCache[K, V]{
+		codec:  o.codec,
+		seed:   maphash.MakeSeed(),
+		shards: make([]*lruCache[K, V], shards),
+	}
+	for i := range sc.shards {
+		sc.shards[i] = newLRUCache[K, V](shardCapacity(capacity, shards, i), nil, o)
+	}
+	// одна фоновая очистка на все сегменты
+	if o.janitorInterval > 0 {
+		sc.janitor = startJanitor(o.janitorCtx, o.janitorInterval, func() {
+			for _, shard := range sc.shards {
+				shard.removeExpired()
+			}
+		})
+	}
+	return sc
+}
+
+// Ёмкость i-го сегмента: остаток от деления достаётся первым сегментам,
+// так что в сумме ёмкости сегментов равны capacity.
+func shardCapacity(capacity, shards, i int) int {
+	if i < capacity%shards {
+		return capacity/shards + 1
+	}
+	return capacity / shards
+}
+
+func (sc *shardedCache[K, V]) shard(key K) *lruCache[K, V] {
+	return sc.shards[hashKey(sc.seed, key)%uint64(len(sc.shards))]
+}
+
+// Хэш ключа, равные по == ключи получают одинаковый хэш.
+// Строки и числа встроенных типов хэшируются напрямую, остальные ключи - поэлементно через reflect.
+func hashKey[K comparable](seed maphash.Seed, key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(seed, k)
+	case Key:
+		return maphash.String(seed, string(k))
+	case int:
+		return hashUint(seed, uint64(k))
+	case int8:
+		return hashUint(seed, uint64(k))
+	case int16:
+		return hashUint(seed, uint64(k))
+	case int32:
+		return hashUint(seed, uint64(k))
+	case int64:
+		return hashUint(seed, uint64(k))
+	case uint:
+		return hashUint(seed, uint64(k))
+	case uint8:
+		return hashUint(seed, uint64(k))
+	case uint16:
+		return hashUint(seed, uint64(k))
+	case uint32:
+		return hashUint(seed, uint64(k))
+	case uint64:
+		return hashUint(seed, k)
+	case uintptr:
+		return hashUint(seed, uint64(k))
+	case float32:
+		return hashUint(seed, floatBits(float64(k)))
+	case float64:
+		return hashUint(seed, floatBits(k))
+	}
+	var h maphash.Hash
+	h.SetSeed(seed)
+	writeValue(&h, reflect.ValueOf(key))
+	return h.Sum64()
+}
+
+func hashUint(seed maphash.Seed, v uint64) uint64 {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return maphash.Bytes(seed, buf[:])
+}
+
+// Биты числа, одинаковые для 0 и -0: эти значения равны по ==.
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
+
+// Записать значение в h по правилам сравнения ==: структуры и массивы - поэлементно,
+// указатели и каналы - по адресу, интерфейсы - по динамическому значению.
+func writeValue(h *maphash.Hash, v reflect.Value) {
+	var buf [8]byte
+	writeUint := func(u uint64) {
+		binary.LittleEndian.PutUint64(buf[:], u)
+		_, _ = h.Write(buf[:])
+	}
+	switch v.Kind() {
+	case reflect.String:
+		_, _ = h.WriteString(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(floatBits(real(v.Complex())))
+		writeUint(floatBits(imag(v.Complex())))
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint(uint64(v.Pointer()))
+	case reflect.Interface:
+		if !v.IsNil() {
+			writeValue(h, v.Elem())
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			// поля _ не участвуют в сравнении
+			if v.Type().Field(i).Name != "_" {
+				writeValue(h, v.Field(i))
+			}
+		}
+	default:
+		// nil-интерфейс в качестве ключа
+	}
+}
+
+func (sc *shardedCache[K, V]) Set(key K, value V) bool {
+	return sc.shard(key).Set(key, value)
+}
+
+func (sc *shardedCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
+	return sc.shard(key).SetWithTTL(key, value, ttl)
+}
+
+func (sc *shardedCache[K, V]) Get(key K) (V, bool) {
+	return sc.shard(key).Get(key)
+}
+
+func (sc *shardedCache[K, V]) Peek(key K) (V, bool) {
+	return sc.shard(key).Peek(key)
+}
+
+func (sc *shardedCache[K, V]) Contains(key K) bool {
+	return sc.shard(key).Contains(key)
+}
+
+func (sc *shardedCache[K, V]) GetOrSet(key K, loader func() (V, error)) (V, error) {
+	return sc.shard(key).GetOrSet(key, loader)
+}
+
+func (sc *shardedCache[K, V]) Delete(key K) bool {
+	return sc.shard(key).Delete(key)
+}
+
+// Ключи всех сегментов от недавно использованных к давно использованным.
+// Сегменты объединяются по времени последнего обращения, поэтому между сегментами
+// порядок приблизительный: обращения в пределах точности часов могут идти в любом порядке.
+// Для политик, отличных от LRU, сегменты тоже объединяются по давности использования.
+func (sc *shardedCache[K, V]) Keys() []K {
+	type usedKey struct {
+		key  K
+		used time.Time
+	}
+	var used []usedKey
+	for _, shard := range sc.shards {
+		used = append(used, liveEntries(shard, func(e *cacheEntry[K, V]) usedKey {
+			return usedKey{key: e.key, used: e.used}
+		})...)
+	}
+	slices.SortStableFunc(used, func(a, b usedKey) int { return b.used.Compare(a.used) })
+	keys := make([]K, len(used))
+	for i, u := range used {
+		keys[i] = u.key
+	}
+	return keys
+}
+
+func (sc *shardedCache[K, V]) Len() int {
+	total := 0
+	for _, shard := range sc.shards {
+		total += shard.Len()
+	}
+	return total
+}
+
+// Новая ёмкость делится между сегментами так же, как при создании кэша.
+// Число сегментов не меняется, поэтому при ёмкости меньше их числа
+// в части сегментов не остаётся места.
+func (sc *shardedCache[K, V]) Resize(capacity int) int {
+	evicted := 0
+	for i, shard := range sc.shards {
+		evicted += shard.Resize(shardCapacity(capacity, len(sc.shards), i))
+	}
+	return evicted
+}
+
+func (sc *shardedCache[K, V]) Clear() {
+	for _, shard := range sc.shards {
+		shard.Clear()
+	}
+}
+
+func (sc *shardedCache[K, V]) Close() {
+	sc.janitor.close()
+}
+
+func (sc *shardedCache[K, V]) OnEvict(fn func(key K, value V, reason EvictionReason)) {
+	for _, shard := range sc.shards {
+		shard.OnEvict(fn)
+	}
+}
+
+// Сумма статистики сегментов, каждый сегмент читается под своей блокировкой.
+func (sc *shardedCache[K, V]) Stats() Stats {
+	var total Stats
+	for _, shard := range sc.shards {
+		stats := shard.Stats()
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.Evictions += stats.Evictions
+		total.Size += stats.Size
+		total.Cost += stats.Cost
+	}
+	return total
+}
+
+// Снимок всех сегментов подряд. Порядок элементов внутри сегмента сохраняется,
+// при загрузке ключи заново распределяются по сегментам.
+func (sc *shardedCache[K, V]) SaveTo(w io.Writer) error {
+	var entries []snapshotEntry[K, V]
+	for _, shard := range sc.shards {
+		entries = append(entries, shard.snapshot()...)
+	}
+	return writeSnapshot(w, sc.codec, entries)
+}
+
+func (sc *shardedCache[K, V]) LoadFrom(r io.Reader) error {
+	entries, err := readSnapshot[K, V](r, sc.codec)
+	if err != nil {
+		return err
+	}
+	byShard := make(map[*lruCache[K, V]][]snapshotEntry[K, V], len(sc.shards))
+	for _, e := range entries {
+		shard := sc.shard(e.Key)
+		byShard[shard] = append(byShard[shard], e)
+	}
+	for shard, entries := range byShard {
+		shard.mu.Lock()
+		shard.restore(entries)
+		shard.unlockAndNotify()
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package hw04lrucache
+
+import (
+	"context"
+	"hash/maphash"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedCache(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		c := NewShardedCache[string, int](100, 4)
+
+		require.False(t, c.Set("aaa", 100))
+		require.True(t, c.Set("aaa", 200))
+
+		val, ok := c.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 200, val)
+
+		_, ok = c.Get("bbb")
+		require.False(t, ok)
+
+		c.Clear()
+		_, ok = c.Get("aaa")
+		require.False(t, ok)
+	})
+
+	t.Run("capacity is split between shards", func(t *testing.T) {
+		c := NewShardedCache[int, int](64, 8)
+		for i := 0; i < 1000; i++ {
+			c.Set(i, i)
+		}
+		stats := c.Stats()
+		require.LessOrEqual(t, stats.Size, 64)
+		require.Equal(t, uint64(1000-stats.Size), stats.Evictions)
+
+		// последний добавленный элемент всегда в кэше
+		val, ok := c.Get(999)
+		require.True(t, ok)
+		require.Equal(t, 999, val)
+	})
+
+	t.Run("capacity is not exceeded when it is not divisible by shards", func(t *testing.T) {
+		for _, tc := range []struct{ capacity, shards int }{{5, 64}, {10, 3}, {1, 4}, {7, 7}} {
+			c := NewShardedCache[int, int](tc.capacity, tc.shards)
+			for i := 0; i < 1000; i++ {
+				c.Set(i, i)
+			}
+			require.LessOrEqual(t, c.Len(), tc.capacity, "capacity %d, shards %d", tc.capacity, tc.shards)
+
+			c.Resize(tc.capacity + 1)
+			for i := 0; i < 1000; i++ {
+				c.Set(i, i)
+			}
+			require.LessOrEqual(t, c.Len(), tc.capacity+1, "resized capacity %d, shards %d", tc.capacity+1, tc.shards)
+		}
+	})
+
+	t.Run("keys in recency order across shards", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewShardedCache[int, int](100, 8, withClock(clock.Now))
+		// сегменты упорядочиваются по времени обращения, поэтому часы идут между обращениями
+		for i := 0; i < 20; i++ {
+			c.Set(i, i)
+			clock.Advance(time.Millisecond)
+		}
+		c.Get(5)
+		clock.Advance(time.Millisecond)
+		c.SetWithTTL(3, 3, time.Second)
+		clock.Advance(time.Millisecond)
+		c.Peek(0)
+
+		keys := c.Keys()
+		require.Len(t, keys, 20)
+		require.Equal(t, []int{3, 5, 19, 18, 17}, keys[:5])
+		require.Equal(t, 0, keys[19])
+
+		clock.Advance(time.Second)
+		require.Equal(t, []int{5, 19, 18}, c.Keys()[:3])
+		require.Equal(t, 19, c.Len())
+	})
+
+	t.Run("eviction callback and ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewShardedCache[Key, int](10, 3, withClock(clock.Now), WithDefaultTTL(time.Second),
+			WithJanitor(context.Background(), time.Millisecond))
+		defer c.Close()
+
+		var mu sync.Mutex
+		evicted := 0
+		c.OnEvict(func(Key, int, EvictionReason) {
+			mu.Lock()
+			defer mu.Unlock()
+			evicted++
+		})
+		for i := 0; i < 5; i++ {
+			c.Set(Key(strconv.Itoa(i)), i)
+		}
+		clock.Advance(time.Second)
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return evicted == 5
+		}, time.Second, time.Millisecond)
+		require.Equal(t, 0, c.Stats().Size)
+	})
+
+	t.Run("keys of any comparable type", func(t *testing.T) {
+		type point struct{ x, y int }
+		c := NewShardedCache[point, string](10, 4)
+		c.Set(point{1, 2}, "a")
+		val, ok := c.Get(point{1, 2})
+		require.True(t, ok)
+		require.Equal(t, "a", val)
+
+		seed := maphash.MakeSeed()
+		require.Equal(t, hashKey(seed, point{1, 2}), hashKey(seed, point{1, 2}))
+		require.Equal(t, hashKey(seed, Key("a")), hashKey(seed, "a"))
+	})
+
+	t.Run("equal keys hash equally", func(t *testing.T) {
+		seed := maphash.MakeSeed()
+		negZero := math.Copysign(0, -1)
+		require.Equal(t, hashKey(seed, 0.0), hashKey(seed, negZero))
+		require.Equal(t, hashKey(seed, float32(0)), hashKey(seed, float32(negZero)))
+		require.Equal(t, hashKey(seed, complex(0, 0)), hashKey(seed, complex(negZero, negZero)))
+
+		type celsius float64
+		require.Equal(t, hashKey(seed, celsius(0)), hashKey(seed, celsius(negZero)))
+		type reading struct {
+			Sensor string
+			Value  float64
+			_      int
+		}
+		require.Equal(t, hashKey(seed, reading{"a", 0, 1}), hashKey(seed, reading{"a", negZero, 2}))
+		require.Equal(t, hashKey[any](seed, 0.0), hashKey[any](seed, negZero))
+		require.Equal(t, hashKey[any](seed, nil), hashKey[any](seed, nil))
+
+		c := NewShardedCache[float64, int](100, 16)
+		c.Set(0.0, 1)
+		val, ok := c.Get(negZero)
+		require.True(t, ok)
+		require.Equal(t, 1, val)
+	})
+
+	t.Run("integer keys of every kind are spread between shards", func(t *testing.T) {
+		seed := maphash.MakeSeed()
+		require.NotEqual(t, hashKey(seed, uint16(1)), hashKey(seed, uint16(2)))
+		require.NotEqual(t, hashKey(seed, int8(-1)), hashKey(seed, int8(1)))
+		require.Equal(t, hashKey(seed, uint(7)), hashKey(seed, uintptr(7)))
+		require.Zero(t, testing.AllocsPerRun(100, func() {
+			hashKey(seed, uint16(7))
+			hashKey(seed, "key")
+		}))
+	})
+}
+
+func benchmarkParallel(b *testing.B, c CacheOf[int, int]) {
+	b.Helper()
+	const keys = 10_000
+	for i := 0; i < keys; i++ {
+		c.Set(i, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			key := r.Intn(keys * 2)
+			if _, ok := c.Get(key); !ok {
+				c.Set(key, key)
+			}
+		}
+	})
+}
+
+// Сравнение при разном числе потоков: go test -bench Parallel -cpu 1,2,4,8.
+func BenchmarkCacheParallel(b *testing.B) {
+	benchmarkParallel(b, NewCacheOf[int, int](5_000))
+}
+
+func BenchmarkShardedCacheParallel(b *testing.B) {
+	benchmarkParallel(b, NewShardedCache[int, int](5_000, 64))
+}
@@ -0,0 +1,149 @@
+package hw04lrucache
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	ErrSnapshotVersion = errors.New("unsupported snapshot version")
+	ErrSnapshotCorrupt = errors.New("corrupt snapshot")
+)
+
+const snapshotVersion = 1
+
+// Кодек, которым записываются ключи и значения снимка кэша.
+// Ключи и значения должны поддерживаться кодеком: например, для gob
+// конкретные типы значений interface{} нужно зарегистрировать через gob.Register.
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+type Encoder interface {
+	Encode(v any) error
+}
+
+type Decoder interface {
+	Decode(v any) error
+}
+
+var (
+	GobCodec  Codec = gobCodec{}
+	JSONCodec Codec = jsonCodec{}
+)
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+
+func (gobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// Снимок - заголовок и Count элементов от кандидата на вытеснение к наиболее ценному,
+// так что их последовательное добавление восстанавливает порядок LRU.
+type snapshotHeader struct {
+	Version int `json:"version"`
+	Count   int `json:"count"`
+}
+
+type snapshotEntry[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+	// нулевое время - элемент не устаревает
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func writeSnapshot[K comparable, V any](w io.Writer, codec Codec, entries []snapshotEntry[K, V]) error {
+	enc := codec.NewEncoder(w)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion, Count: len(entries)}); err != nil {
+		return fmt.Errorf("write snapshot header: %w", err)
+	}
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return fmt.Errorf("write snapshot entry %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func readSnapshot[K comparable, V any](r io.Reader, codec Codec) ([]snapshotEntry[K, V], error) {
+	dec := codec.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("read snapshot header: %w", err)
+	}
+	if header.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrSnapshotVersion, header.Version)
+	}
+
+	if header.Count < 0 {
+		return nil, fmt.Errorf("%w: negative entry count %d", ErrSnapshotCorrupt, header.Count)
+	}
+
+	// Count не проверен, поэтому память под элементы выделяется по мере чтения
+	var entries []snapshotEntry[K, V]
+	for i := 0; i < header.Count; i++ {
+		var e snapshotEntry[K, V]
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("read snapshot entry %d: %w", i, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Записать неустаревшие элементы вместе со временем их устаревания.
+// Кодирование выполняется без блокировки кэша.
+func (lc *lruCache[K, V]) SaveTo(w io.Writer) error {
+	return writeSnapshot(w, lc.codec, lc.snapshot())
+}
+
+func (lc *lruCache[K, V]) snapshot() []snapshotEntry[K, V] {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	now := lc.now()
+	entries := make([]snapshotEntry[K, V], 0, len(lc.items))
+	lc.policy.each(func(e *cacheEntry[K, V]) {
+		if !e.expired(now) {
+			entries = append(entries, snapshotEntry[K, V]{Key: e.key, Value: e.value, ExpiresAt: e.expiresAt})
+		}
+	})
+	return entries
+}
+
+// Добавить элементы снимка к содержимому кэша, как если бы они записывались по очереди:
+// они становятся самыми недавно использованными, устаревшие к этому времени пропускаются.
+// Если снимок не удалось прочитать, кэш не меняется.
+func (lc *lruCache[K, V]) LoadFrom(r io.Reader) error {
+	entries, err := readSnapshot[K, V](r, lc.codec)
+	if err != nil {
+		return err
+	}
+	lc.mu.Lock()
+	defer lc.unlockAndNotify()
+	lc.restore(entries)
+	return nil
+}
+
+func (lc *lruCache[K, V]) restore(entries []snapshotEntry[K, V]) {
+	now := lc.now()
+	for _, e := range entries {
+		var ttl time.Duration
+		if !e.ExpiresAt.IsZero() {
+			if ttl = e.ExpiresAt.Sub(now); ttl <= 0 {
+				continue
+			}
+		}
+		lc.set(e.Key, e.Value, ttl)
+	}
+}
@@ -0,0 +1,154 @@
+package hw04lrucache
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run("keeps order and ttl", func(t *testing.T) {
+		for _, codec := range []Codec{GobCodec, JSONCodec} {
+			clock := newFakeClock()
+			c := NewCacheOf[string, int](5, WithCodec(codec), withClock(clock.Now))
+			c.Set("a", 1)
+			c.SetWithTTL("b", 2, time.Minute)
+			c.Set("c", 3)
+			c.Get("a")
+
+			var buf bytes.Buffer
+			require.NoError(t, c.SaveTo(&buf))
+
+			restored := NewCacheOf[string, int](5, WithCodec(codec), withClock(clock.Now))
+			require.NoError(t, restored.LoadFrom(&buf))
+			require.Equal(t, []string{"a", "c", "b"}, restored.Keys())
+			val, ok := restored.Peek("b")
+			require.True(t, ok)
+			require.Equal(t, 2, val)
+
+			// время устаревания не сдвигается при восстановлении
+			clock.Advance(time.Minute)
+			require.Equal(t, []string{"a", "c"}, restored.Keys())
+		}
+	})
+
+	t.Run("skips entries expired before load", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheOf[string, int](5, withClock(clock.Now))
+		c.SetWithTTL("a", 1, time.Second)
+		c.Set("b", 2)
+		var buf bytes.Buffer
+		require.NoError(t, c.SaveTo(&buf))
+
+		clock.Advance(time.Second)
+		restored := NewCacheOf[string, int](5, withClock(clock.Now))
+		require.NoError(t, restored.LoadFrom(&buf))
+		require.Equal(t, []string{"b"}, restored.Keys())
+	})
+
+	t.Run("struct values in json", func(t *testing.T) {
+		type user struct {
+			Name string `json:"name"`
+			Age  int    `json:"age"`
+		}
+		c := NewCacheOf[int, user](5, WithCodec(JSONCodec))
+		c.Set(1, user{Name: "Ann", Age: 30})
+		var buf bytes.Buffer
+		require.NoError(t, c.SaveTo(&buf))
+		require.True(t, json.Valid(bytes.Split(buf.Bytes(), []byte("\n"))[1]))
+
+		restored := NewCacheOf[int, user](5, WithCodec(JSONCodec))
+		require.NoError(t, restored.LoadFrom(&buf))
+		val, ok := restored.Get(1)
+		require.True(t, ok)
+		require.Equal(t, user{Name: "Ann", Age: 30}, val)
+	})
+
+	t.Run("load into smaller cache evicts oldest", func(t *testing.T) {
+		c := NewCacheOf[int, int](10)
+		for i := 0; i < 10; i++ {
+			c.Set(i, i)
+		}
+		var buf bytes.Buffer
+		require.NoError(t, c.SaveTo(&buf))
+
+		restored := NewCacheOf[int, int](3)
+		require.NoError(t, restored.LoadFrom(&buf))
+		require.Equal(t, []int{9, 8, 7}, restored.Keys())
+	})
+
+	t.Run("invalid snapshot leaves cache unchanged", func(t *testing.T) {
+		c := NewCacheOf[string, int](5)
+		for i := 0; i < 3; i++ {
+			c.Set(strconv.Itoa(i), i)
+		}
+		var buf bytes.Buffer
+		require.NoError(t, c.SaveTo(&buf))
+
+		restored := NewCacheOf[string, int](5)
+		restored.Set("x", 0)
+		require.Error(t, restored.LoadFrom(bytes.NewReader(buf.Bytes()[:buf.Len()-3])))
+		require.Equal(t, []string{"x"}, restored.Keys())
+
+		var old bytes.Buffer
+		require.NoError(t, GobCodec.NewEncoder(&old).Encode(snapshotHeader{Version: 0}))
+		require.ErrorIs(t, restored.LoadFrom(&old), ErrSnapshotVersion)
+		require.Equal(t, []string{"x"}, restored.Keys())
+	})
+
+	t.Run("corrupt header", func(t *testing.T) {
+		for _, codec := range []Codec{GobCodec, JSONCodec} {
+			c := NewCacheOf[string, int](5, WithCodec(codec))
+			c.Set("x", 0)
+
+			var negative bytes.Buffer
+			require.NoError(t, codec.NewEncoder(&negative).Encode(snapshotHeader{Version: snapshotVersion, Count: -1}))
+			require.ErrorIs(t, c.LoadFrom(&negative), ErrSnapshotCorrupt)
+
+			// огромное число элементов без самих элементов - ошибка чтения, а не выделение памяти
+			var huge bytes.Buffer
+			enc := codec.NewEncoder(&huge)
+			require.NoError(t, enc.Encode(snapshotHeader{Version: snapshotVersion, Count: math.MaxInt}))
+			require.NoError(t, enc.Encode(snapshotEntry[string, int]{Key: "a", Value: 1}))
+			require.Error(t, c.LoadFrom(&huge))
+
+			require.Equal(t, []string{"x"}, c.Keys())
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		c := NewShardedCache[int, string](100, 4)
+		for i := 0; i < 20; i++ {
+			c.Set(i, strconv.Itoa(i))
+		}
+		var buf bytes.Buffer
+		require.NoError(t, c.SaveTo(&buf))
+
+		restored := NewShardedCache[int, string](100, 8)
+		require.NoError(t, restored.LoadFrom(&buf))
+		require.ElementsMatch(t, c.Keys(), restored.Keys())
+		val, ok := restored.Get(7)
+		require.True(t, ok)
+		require.Equal(t, "7", val)
+	})
+
+	t.Run("interface values in gob", func(t *testing.T) {
+		c := NewCache(5)
+		c.Set("a", 1)
+		c.Set("b", "two")
+		var buf bytes.Buffer
+		require.NoError(t, c.SaveTo(&buf))
+
+		restored := NewCache(5)
+		require.NoError(t, restored.LoadFrom(&buf))
+		val, _ := restored.Get("a")
+		require.Equal(t, 1, val)
+		val, _ = restored.Get("b")
+		require.Equal(t, "two", val)
+	})
+}
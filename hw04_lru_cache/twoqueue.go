@@ -0,0 +1,82 @@
+package hw04lrucache
+
+// Политика 2Q (Johnson, Shasha, 1994): новые элементы попадают в очередь FIFO in,
+// повторные обращения к ним её не меняют. Вытесненные из in ключи запоминаются в ghosts,
+// и если ключ запросят снова, он попадает в основную LRU-очередь main.
+type twoQueuePolicy[K comparable, V any] struct {
+	in     entryList[K, V]
+	main   entryList[K, V]
+	ghosts ghostList[K]
+	// следующий добавляемый элемент был в истории и попадёт в main
+	promote bool
+}
+
+// Доли ёмкости под очередь in и под историю ghosts, рекомендованные авторами.
+const (
+	twoQueueInShare    = 4 // 1/4
+	twoQueueGhostShare = 2 // 1/2
+)
+
+func newTwoQueuePolicy[K comparable, V any]() *twoQueuePolicy[K, V] {
+	return &twoQueuePolicy[K, V]{
+		in:     newEntryList[K, V](),
+		main:   newEntryList[K, V](),
+		ghosts: newGhostList[K](),
+	}
+}
+
+func (p *twoQueuePolicy[K, V]) admit(key K, _, _ int) {
+	_, p.promote = p.ghosts.remove(key)
+}
+
+func (p *twoQueuePolicy[K, V]) add(e *cacheEntry[K, V]) {
+	if p.promote {
+		p.main.pushFront(e, segmentFrequent)
+	} else {
+		p.in.pushFront(e, segmentRecent)
+	}
+	p.promote = false
+}
+
+func (p *twoQueuePolicy[K, V]) hit(e *cacheEntry[K, V], cost int) {
+	p.list(e).setCost(e, cost)
+	if e.segment == segmentFrequent {
+		p.main.entries.MoveToFront(e.elem)
+	}
+}
+
+func (p *twoQueuePolicy[K, V]) remove(e *cacheEntry[K, V]) {
+	p.list(e).remove(e)
+}
+
+func (p *twoQueuePolicy[K, V]) evict(capacity int) *cacheEntry[K, V] {
+	maxIn := max(capacity/twoQueueInShare, 1)
+	if p.in.entries.Len() > 0 && (p.in.cost > maxIn || p.main.entries.Len() == 0) {
+		e := p.in.back()
+		p.in.remove(e)
+		p.ghosts.push(e.key, e.cost)
+		for p.ghosts.cost > max(capacity/twoQueueGhostShare, 1) {
+			p.ghosts.removeOldest()
+		}
+		return e
+	}
+	e := p.main.back()
+	p.main.remove(e)
+	return e
+}
+
+func (p *twoQueuePolicy[K, V]) each(fn func(e *cacheEntry[K, V])) {
+	p.in.each(fn)
+	p.main.each(fn)
+}
+
+func (p *twoQueuePolicy[K, V]) clear() {
+	*p = *newTwoQueuePolicy[K, V]()
+}
+
+func (p *twoQueuePolicy[K, V]) list(e *cacheEntry[K, V]) *entryList[K, V] {
+	if e.segment == segmentFrequent {
+		return &p.main
+	}
+	return &p.in
+}
@@ -0,0 +1,52 @@
+package hw05parallelexecution
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ошибка задачи с её номером в исходном списке.
+type TaskError struct {
+	Index int
+	Err   error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Итог пакета задач, выполнение которого было прервано превышением лимита ошибок
+// или отменой контекста. errors.Is находит как причину прерывания
+// (ErrErrorsLimitExceeded, context.Canceled), так и ошибки отдельных задач.
+type RunError struct {
+	// Причина прерывания.
+	Reason error
+	// Ошибки задач по возрастанию их номеров.
+	Errors     []TaskError
+	Completed  int
+	Failed     int
+	NotStarted int
+}
+
+func (e *RunError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: %d completed, %d failed, %d not started", e.Reason, e.Completed, e.Failed, e.NotStarted)
+	for i := range e.Errors {
+		b.WriteString("; ")
+		b.WriteString(e.Errors[i].Error())
+	}
+	return b.String()
+}
+
+func (e *RunError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors)+1)
+	errs = append(errs, e.Reason)
+	for i := range e.Errors {
+		errs = append(errs, &e.Errors[i])
+	}
+	return errs
+}
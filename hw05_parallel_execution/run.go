@@ -1,97 +1,185 @@
 package hw05parallelexecution
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"sync"
-	"sync/atomic"
+	"time"
 )
 
-var ErrErrorsLimitExceeded = errors.New("errors limit exceeded")
+var (
+	ErrErrorsLimitExceeded = errors.New("errors limit exceeded")
+	ErrTaskTimeout         = errors.New("task timed out")
+)
 
 type Task func() error
 
+// Задача, которая должна завершиться при отмене ctx.
+type ContextTask func(ctx context.Context) error
+
+type Options struct {
+	// Число одновременно выполняемых задач, не меньше 1.
+	Workers int
+	// После MaxErrors ошибок новые задачи не запускаются. MaxErrors <= 0 - ошибки игнорируются.
+	MaxErrors int
+	// Время на выполнение одной задачи, 0 - без ограничения.
+	// Задача, не уложившаяся в срок, считается завершившейся с ErrTaskTimeout,
+	// а если она не реагирует на отмену контекста, то продолжает выполняться в фоне.
+	TaskTimeout time.Duration
+}
+
+// Выполнить задачи в n горутинах, прекратив запуск новых задач после m ошибок.
 func Run(tasks []Task, n, m int) error {
+	contextTasks := make([]ContextTask, len(tasks))
+	for i, task := range tasks {
+		task := task
+		contextTasks[i] = func(context.Context) error { return task() }
+	}
+	return RunContext(context.Background(), contextTasks, Options{Workers: n, MaxErrors: m})
+}
+
+// Выполнить задачи в opts.Workers горутинах.
+// Отмена ctx прекращает запуск новых задач и передаётся выполняющимся, RunContext дожидается
+// их завершения. При превышении лимита ошибок выполняющиеся задачи тоже получают отменённый контекст.
+// Если превышен лимит ошибок или ctx отменён до завершения задач, возвращается *RunError,
+// иначе nil, даже если отдельные задачи завершились с ошибкой.
+func RunContext(ctx context.Context, tasks []ContextTask, opts Options) error {
+	return run(ctx, len(tasks), opts, func(ctx context.Context, index int) error {
+		return tasks[index](ctx)
+	}, nil)
+}
+
+// Применить fn к каждому из inputs в opts.Workers горутинах с тем же лимитом ошибок, что у RunContext.
+// Результаты идут в порядке inputs; для задач, завершившихся с ошибкой или не запущенных,
+// результат - нулевое значение R.
+func Map[T, R any](ctx context.Context, inputs []T, fn func(ctx context.Context, input T) (R, error),
+	opts Options,
+) ([]R, error) {
+	results := make([]R, len(inputs))
+	// задача, переставшая укладываться в TaskTimeout, может записать значение позже,
+	// поэтому в results копируются только значения успешно завершившихся задач
+	values := make([]R, len(inputs))
+	err := run(ctx, len(inputs), opts, func(ctx context.Context, index int) (err error) {
+		values[index], err = fn(ctx, inputs[index])
+		return err
+	}, func(index int) {
+		results[index] = values[index]
+	})
+	return results, err
+}
+
+// Выполнить задачи с номерами от 0 до count-1, onSuccess вызывается для каждой успешно завершившейся задачи.
+func run(ctx context.Context, count int, opts Options, task func(ctx context.Context, index int) error,
+	onSuccess func(index int),
+) error {
 	// 0. Инит
-	var errCount int32
-	var wg sync.WaitGroup
-	numWorkers := n
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	if numWorkers > len(tasks) {
-		numWorkers = len(tasks)
+	var mu sync.Mutex
+	report := RunError{Reason: ErrErrorsLimitExceeded}
+	limitExceeded := func() bool {
+		return opts.MaxErrors > 0 && report.Failed >= opts.MaxErrors
 	}
+	numWorkers := min(max(opts.Workers, 1), count)
 
-	work := make(chan Task)
-	errs := make(chan error, len(tasks))
-	done := make(chan struct{})
-	defer func() {
-		close(errs)
-		if !isDone(done) {
-			close(done)
-		}
-	}()
-
-	// 1. Создать n обработчиков заданий.
+	// 1. Создать обработчиков заданий, при превышении лимита ошибок они отменяют ctx.
+	work := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
 	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go worker(work, errs, &wg)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				index := index
+				err := runTask(ctx, func(ctx context.Context) error {
+					return task(ctx, index)
+				}, opts.TaskTimeout)
+
+				mu.Lock()
+				if err == nil {
+					report.Completed++
+					if onSuccess != nil {
+						onSuccess(index)
+					}
+				} else {
+					report.Failed++
+					report.Errors = append(report.Errors, TaskError{Index: index, Err: err})
+					if limitExceeded() {
+						cancel()
+					}
+				}
+				mu.Unlock()
+			}
+		}()
 	}
-	// 2. Горутина отслеживающаяя превышение лимита ошибок.
-	go errorCountThresholdObserver(errs, done, &errCount, m)
-	// 3. Отправляем работу воркерам, а если накопился критический порог ошибок - выходим.
+
+	// 2. Отправляем работу воркерам, пока не отменён контекст или не превышен лимит ошибок.
+	dispatched := 0
 loop:
-	for _, t := range tasks {
+	for index := 0; index < count; index++ {
 		select {
-		case <-done:
+		case <-ctx.Done():
 			break loop
-		case work <- t:
+		case work <- index:
+			dispatched++
 		}
 	}
-	// 4. Закрываем канал задач, ждем завершения и выходим.
+	// 3. Закрываем канал задач, ждем завершения и выходим.
 	close(work)
 	wg.Wait()
 
-	if m > 0 && errCount >= int32(m) {
-		return ErrErrorsLimitExceeded
+	report.NotStarted = count - dispatched
+	switch {
+	case limitExceeded():
+	// отмена ctx сообщается, даже если все задачи успели запуститься
+	case parent.Err() != nil:
+		report.Reason = parent.Err()
+	default:
+		return nil
 	}
-	return nil
+	slices.SortFunc(report.Errors, func(a, b TaskError) int { return a.Index - b.Index })
+	return &report
 }
 
-// Done канал закрывается если превышен лимит ошибок.
-// Если нет - то потребуется закрыть его при выходе из основной горутины, чтобы observer мог завершиться.
-func isDone(done <-chan struct{}) bool {
-	select {
-	case <-done:
-		return true
-	default:
-		return false
+// Выполнить задачу, ожидая её не дольше timeout, даже если контекст отменён раньше.
+func runTask(ctx context.Context, task ContextTask, timeout time.Duration) error {
+	if timeout <= 0 {
+		return task(ctx)
 	}
-}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrTaskTimeout)
+	defer cancel()
 
-// Выполняет задачу, если произошла ошибка - пишет в канал ошибок.
-func worker(work <-chan Task, errs chan<- error, wg *sync.WaitGroup) {
-	defer func() {
-		wg.Done()
+	result := make(chan error, 1)
+	go func() {
+		result <- task(ctx)
 	}()
-
-	for t := range work {
-		err := t()      // Получили задачу, выполняем.
-		if err != nil { // Ошибка - передать в канал ошибок.
-			errs <- err
-		}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return timeoutError(ctx, err)
+	case <-timer.C:
+	}
+	// дождаться срабатывания срока в самом контексте, чтобы задача увидела
+	// context.DeadlineExceeded, а не отмену при выходе
+	<-ctx.Done()
+	select {
+	case err := <-result:
+		return timeoutError(ctx, err)
+	default:
+		return ErrTaskTimeout
 	}
 }
 
-// Отслеживает канал ошибок и прерывает выполнение через done канал в случае превышения порога.
-func errorCountThresholdObserver(errs <-chan error, done chan<- struct{}, errCount *int32, maxErrors int) {
-	for err := range errs {
-		if err != nil {
-			atomic.AddInt32(errCount, 1)
-		}
-		if maxErrors > 0 {
-			if atomic.LoadInt32(errCount) >= int32(maxErrors) {
-				close(done)
-				return
-			}
-		}
+// Если срок задачи истёк, её ошибка дополняется ErrTaskTimeout,
+// так что errors.Is находит и ErrTaskTimeout, и исходную ошибку.
+func timeoutError(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(context.Cause(ctx), ErrTaskTimeout) || errors.Is(err, ErrTaskTimeout) {
+		return err
 	}
+	return fmt.Errorf("%w: %w", ErrTaskTimeout, err)
 }
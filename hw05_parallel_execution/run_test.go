@@ -1,9 +1,12 @@
 package hw05parallelexecution
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -132,3 +135,353 @@ func TestRun(t *testing.T) {
 		require.Equal(t, runTasksCount, int32(tasksCount), "not all tasks were completed")
 	})
 }
+
+func TestRunContext(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("cancel stops dispatch", func(t *testing.T) {
+		tasksCount := 50
+		tasks := make([]ContextTask, 0, tasksCount)
+		var runTasksCount int32
+
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				atomic.AddInt32(&runTasksCount, 1)
+				select {
+				case <-time.After(10 * time.Millisecond):
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 35*time.Millisecond)
+		defer cancel()
+		err := RunContext(ctx, tasks, Options{Workers: 2})
+
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, runTasksCount, int32(tasksCount), "tasks were started after cancel")
+	})
+
+	t.Run("task timeout counts as error", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		tasksCount := 20
+		tasks := make([]ContextTask, 0, tasksCount)
+		var runTasksCount int32
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, func(context.Context) error {
+				atomic.AddInt32(&runTasksCount, 1)
+				// задача не реагирует на отмену контекста
+				<-release
+				return nil
+			})
+		}
+
+		workersCount := 2
+		maxErrorsCount := 3
+		start := time.Now()
+		err := RunContext(context.Background(), tasks, Options{
+			Workers:     workersCount,
+			MaxErrors:   maxErrorsCount,
+			TaskTimeout: 10 * time.Millisecond,
+		})
+
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		runTasks := atomic.LoadInt32(&runTasksCount)
+		require.LessOrEqual(t, runTasks, int32(workersCount+maxErrorsCount), "extra tasks were started")
+		require.Less(t, time.Since(start), time.Second, "hanging tasks were awaited")
+	})
+
+	t.Run("tasks see their deadline", func(t *testing.T) {
+		var errs []error
+		var mu sync.Mutex
+		task := func(ctx context.Context) error {
+			<-ctx.Done()
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, ctx.Err())
+			return ctx.Err()
+		}
+
+		err := RunContext(context.Background(), []ContextTask{task, task}, Options{
+			Workers:     2,
+			MaxErrors:   2,
+			TaskTimeout: 5 * time.Millisecond,
+		})
+		// успела задача вернуться после дедлайна или нет, её ошибка - ErrTaskTimeout
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.Len(t, runErr.Errors, 2)
+		for _, taskErr := range runErr.Errors {
+			require.ErrorIs(t, taskErr.Err, ErrTaskTimeout)
+			if !errors.Is(taskErr.Err, context.DeadlineExceeded) {
+				require.Equal(t, ErrTaskTimeout, taskErr.Err)
+			}
+		}
+		// задачи могут завершиться уже после того, как их перестали ждать
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(errs) == 2
+		}, time.Second, time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, err := range errs {
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("error returned after deadline keeps its cause", func(t *testing.T) {
+		errTask := errors.New("task failed")
+		expired, cancel := context.WithTimeoutCause(context.Background(), 0, ErrTaskTimeout)
+		defer cancel()
+		err := timeoutError(expired, errTask)
+		require.ErrorIs(t, err, ErrTaskTimeout)
+		require.ErrorIs(t, err, errTask)
+		require.NoError(t, timeoutError(expired, nil))
+
+		// отмена извне - не истечение срока задачи
+		cancelled, cancelParent := context.WithCancel(context.Background())
+		cancelParent()
+		require.Equal(t, context.Canceled, timeoutError(cancelled, context.Canceled))
+	})
+
+	t.Run("errors limit cancels running tasks", func(t *testing.T) {
+		errTask := errors.New("task failed")
+		var canceled int32
+		tasks := []ContextTask{
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				atomic.AddInt32(&canceled, 1)
+				return nil
+			},
+			func(context.Context) error {
+				return errTask
+			},
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: 2, MaxErrors: 1})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.Equal(t, int32(1), canceled)
+	})
+
+	t.Run("all tasks done", func(t *testing.T) {
+		var runTasksCount int32
+		tasks := make([]ContextTask, 10)
+		for i := range tasks {
+			tasks[i] = func(context.Context) error {
+				atomic.AddInt32(&runTasksCount, 1)
+				return nil
+			}
+		}
+
+		require.NoError(t, RunContext(context.Background(), tasks, Options{Workers: 3, TaskTimeout: time.Second}))
+		require.Equal(t, int32(10), runTasksCount)
+		require.NoError(t, RunContext(context.Background(), nil, Options{}))
+	})
+}
+
+func TestRunError(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("limit exceeded report", func(t *testing.T) {
+		tasksCount := 50
+		tasks := make([]Task, 0, tasksCount)
+		taskErrs := make([]error, tasksCount)
+		for i := 0; i < tasksCount; i++ {
+			i := i
+			taskErrs[i] = fmt.Errorf("error from task %d", i)
+			tasks = append(tasks, func() error {
+				time.Sleep(time.Millisecond * time.Duration(rand.Intn(10)))
+				// чётные задачи завершаются с ошибкой
+				if i%2 == 0 {
+					return taskErrs[i]
+				}
+				return nil
+			})
+		}
+
+		err := Run(tasks, 4, 5)
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.GreaterOrEqual(t, runErr.Failed, 5)
+		require.Len(t, runErr.Errors, runErr.Failed)
+		require.Positive(t, runErr.NotStarted)
+		require.Equal(t, tasksCount, runErr.Completed+runErr.Failed+runErr.NotStarted)
+
+		for i, taskErr := range runErr.Errors {
+			require.Zero(t, taskErr.Index%2)
+			require.ErrorIs(t, err, taskErrs[taskErr.Index])
+			if i > 0 {
+				require.Greater(t, taskErr.Index, runErr.Errors[i-1].Index)
+			}
+		}
+		var taskErr *TaskError
+		require.ErrorAs(t, err, &taskErr)
+		require.Equal(t, runErr.Errors[0].Index, taskErr.Index)
+	})
+
+	t.Run("error message", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		err := &RunError{
+			Reason:     ErrErrorsLimitExceeded,
+			Errors:     []TaskError{{Index: 1, Err: errBoom}, {Index: 3, Err: ErrTaskTimeout}},
+			Completed:  2,
+			Failed:     2,
+			NotStarted: 6,
+		}
+		require.Equal(t, "errors limit exceeded: 2 completed, 2 failed, 6 not started; "+
+			"task 1: boom; task 3: task timed out", err.Error())
+		require.ErrorIs(t, err, ErrTaskTimeout)
+	})
+
+	t.Run("cancelled batch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		tasks := []ContextTask{
+			func(context.Context) error {
+				cancel()
+				// воркер занят, пока диспетчер не заметит отмену
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			},
+			func(context.Context) error { return nil },
+		}
+
+		err := RunContext(ctx, tasks, Options{Workers: 1})
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotErrorIs(t, err, ErrErrorsLimitExceeded)
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.Equal(t, 1, runErr.Completed)
+		require.Equal(t, 1, runErr.NotStarted)
+		require.Empty(t, runErr.Errors)
+	})
+
+	t.Run("cancelled after all tasks started", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{}, 2)
+		tasks := make([]ContextTask, 2)
+		for i := range tasks {
+			tasks[i] = func(ctx context.Context) error {
+				started <- struct{}{}
+				<-ctx.Done()
+				return ctx.Err()
+			}
+		}
+		go func() {
+			<-started
+			<-started
+			cancel()
+		}()
+
+		err := RunContext(ctx, tasks, Options{Workers: 2})
+		require.ErrorIs(t, err, context.Canceled)
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.Equal(t, context.Canceled, runErr.Reason)
+		require.Equal(t, 2, runErr.Failed)
+		require.Equal(t, 0, runErr.NotStarted)
+	})
+
+	t.Run("errors under the limit are not reported", func(t *testing.T) {
+		tasks := []Task{
+			func() error { return errors.New("failed") },
+			func() error { return nil },
+		}
+		require.NoError(t, Run(tasks, 2, 2))
+	})
+}
+
+func TestMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("results in input order", func(t *testing.T) {
+		inputs := make([]int, 100)
+		for i := range inputs {
+			inputs[i] = i
+		}
+
+		var running, maxRunning int32
+		workersCount := 8
+		results, err := Map(context.Background(), inputs, func(_ context.Context, v int) (string, error) {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				prev := atomic.LoadInt32(&maxRunning)
+				if current <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * time.Duration(rand.Intn(5)))
+			return strconv.Itoa(v * v), nil
+		}, Options{Workers: workersCount})
+
+		require.NoError(t, err)
+		require.Len(t, results, len(inputs))
+		for i, result := range results {
+			require.Equal(t, strconv.Itoa(i*i), result)
+		}
+		require.LessOrEqual(t, maxRunning, int32(workersCount), "concurrency limit exceeded")
+	})
+
+	t.Run("errors limit", func(t *testing.T) {
+		inputs := make([]int, 50)
+		for i := range inputs {
+			inputs[i] = i + 1
+		}
+		errOdd := errors.New("odd input")
+
+		results, err := Map(context.Background(), inputs, func(_ context.Context, v int) (int, error) {
+			time.Sleep(time.Millisecond)
+			if v%2 == 1 {
+				return -1, errOdd
+			}
+			return v, nil
+		}, Options{Workers: 2, MaxErrors: 3})
+
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.ErrorIs(t, err, errOdd)
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.Len(t, results, len(inputs))
+
+		failed := make(map[int]bool)
+		for _, taskErr := range runErr.Errors {
+			failed[taskErr.Index] = true
+		}
+		completed := 0
+		for i, result := range results {
+			switch {
+			case failed[i]:
+				require.Zero(t, result, "result of failed task %d", i)
+			case result != 0:
+				require.Equal(t, inputs[i], result)
+				completed++
+			}
+		}
+		require.Equal(t, runErr.Completed, completed)
+	})
+
+	t.Run("timed out results are dropped", func(t *testing.T) {
+		release := make(chan struct{})
+		finished := make(chan struct{})
+		results, err := Map(context.Background(), []int{1, 2}, func(_ context.Context, v int) (int, error) {
+			if v == 2 {
+				// задача не реагирует на отмену и записывает результат уже после выхода из Map
+				<-release
+				defer close(finished)
+			}
+			return v * 10, nil
+		}, Options{Workers: 2, MaxErrors: 1, TaskTimeout: 10 * time.Millisecond})
+
+		require.ErrorIs(t, err, ErrTaskTimeout)
+		close(release)
+		<-finished
+		require.Equal(t, []int{10, 0}, results)
+	})
+}